@@ -0,0 +1,95 @@
+package jl
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// clefFields maps the reserved fields of CLEF events to the names of the fields they are decoded to.
+var clefFields = map[string]string{
+	"@t":  "timestamp",
+	"@l":  "level",
+	"@m":  "message",
+	"@x":  "exception",
+	"@i":  "eventId",
+	"@tr": "traceId",
+	"@sp": "spanId",
+}
+
+// CLEFDecoder decodes events in the Compact Log Event Format written by Serilog and Seq, which are JSON objects with
+// reserved fields prefixed with @:
+//
+//	{"@t":"2019-01-01T00:00:00Z","@l":"Warning","@mt":"Disk {Drive} is {Percent}% full","Drive":"C:","Percent":90}
+//
+// Reserved fields are renamed in the entry's Partials to the fields printers look for, like timestamp and level, and
+// Raw is left as the original line. Events without a rendered message have their message template rendered with the
+// event's properties. Events without a level are Information.
+var CLEFDecoder Decoder = DecoderFunc(func(line []byte) (*Entry, bool) {
+	var event map[string]json.RawMessage
+	if err := json.Unmarshal(line, &event); err != nil || event["@t"] == nil {
+		return nil, false
+	}
+	obj := make(map[string]json.RawMessage, len(event))
+	for k, v := range event {
+		switch {
+		case strings.HasPrefix(k, "@@"):
+			obj[k[1:]] = v
+		case clefFields[k] != "":
+			obj[clefFields[k]] = v
+		case !strings.HasPrefix(k, "@"):
+			obj[k] = v
+		}
+	}
+	if _, ok := obj["level"]; !ok {
+		obj["level"] = marshalString("Information")
+	}
+	if _, ok := obj["message"]; !ok {
+		var template string
+		if json.Unmarshal(event["@mt"], &template) == nil {
+			var renderings []string
+			_ = json.Unmarshal(event["@r"], &renderings)
+			obj["message"] = marshalString(renderTemplate(template, event, renderings))
+		}
+	}
+	return &Entry{Partials: obj, Raw: line}, true
+})
+
+// renderTemplate renders a message template, replacing each {Property} with its value. Properties with a format, like
+// {Elapsed:0.00}, are replaced by the next of the renderings instead, if there is one.
+func renderTemplate(template string, properties map[string]json.RawMessage, renderings []string) string {
+	var b strings.Builder
+	for i := 0; i < len(template); i++ {
+		c := template[i]
+		if (c == '{' || c == '}') && i+1 < len(template) && template[i+1] == c {
+			b.WriteByte(c)
+			i++
+			continue
+		}
+		end := strings.IndexByte(template[i:], '}')
+		if c != '{' || end < 0 {
+			b.WriteByte(c)
+			continue
+		}
+		token := template[i+1 : i+end]
+		i += end
+		name := strings.TrimLeft(token, "@$")
+		if strings.Contains(name, ":") && len(renderings) > 0 {
+			b.WriteString(renderings[0])
+			renderings = renderings[1:]
+			continue
+		}
+		if j := strings.IndexAny(name, ",:"); j >= 0 {
+			name = name[:j]
+		}
+		if v, ok := properties[name]; ok {
+			var s string
+			if json.Unmarshal(v, &s) != nil {
+				s = string(v)
+			}
+			b.WriteString(s)
+		} else {
+			b.WriteString("{" + token + "}")
+		}
+	}
+	return b.String()
+}
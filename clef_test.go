@@ -0,0 +1,43 @@
+package jl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCLEFDecoder(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		entry string
+	}{{
+		name:  "rendered",
+		line:  `{"@t":"2019-01-01T00:00:00Z","@m":"Hello, World","@i":"a1b2","User":"World"}`,
+		entry: `{"User":"World","eventId":"a1b2","level":"Information","message":"Hello, World","timestamp":"2019-01-01T00:00:00Z"}`,
+	}, {
+		name:  "template",
+		line:  `{"@t":"2019-01-01T00:00:00Z","@l":"Warning","@mt":"Disk {Drive} is {Percent}% full, {{escaped}} {Missing}","Drive":"C:","Percent":90}`,
+		entry: `{"Drive":"C:","Percent":90,"level":"Warning","message":"Disk C: is 90% full, {escaped} {Missing}","timestamp":"2019-01-01T00:00:00Z"}`,
+	}, {
+		name:  "renderings",
+		line:  `{"@t":"2019-01-01T00:00:00Z","@mt":"Took {Elapsed:0.00} ms for {@Request}","@r":["1.50"],"Elapsed":1.5,"Request":{"path":"/"}}`,
+		entry: `{"Elapsed":1.5,"Request":{"path":"/"},"level":"Information","message":"Took 1.50 ms for {\"path\":\"/\"}","timestamp":"2019-01-01T00:00:00Z"}`,
+	}, {
+		name:  "exception",
+		line:  `{"@t":"2019-01-01T00:00:00Z","@l":"Error","@m":"failed","@x":"System.Exception: boom","@@t":"escaped"}`,
+		entry: `{"@t":"escaped","exception":"System.Exception: boom","level":"Error","message":"failed","timestamp":"2019-01-01T00:00:00Z"}`,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			entry, ok := CLEFDecoder.Decode([]byte(test.line))
+			require.True(t, ok)
+			assert.Equal(t, test.entry, string(marshalRaw(entry.Partials)))
+			assert.Equal(t, test.line, string(entry.Raw))
+		})
+	}
+
+	_, ok := CLEFDecoder.Decode([]byte(`{"message":"not clef"}`))
+	assert.False(t, ok)
+}
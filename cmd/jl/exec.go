@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"github.com/mightyguava/jl"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// stderrSource is the source of the entries a child process writes to stderr.
+const stderrSource = "stderr"
+
+// exitCode is returned by run to exit jl with a specific code.
+type exitCode int
+
+func (c exitCode) Error() string {
+	return fmt.Sprintf("exit status %d", int(c))
+}
+
+// runCommand runs the command, printing what it writes to stdout and stderr with printer, and returns an exitCode
+// error with the command's exit code if it failed. SIGTERM is forwarded to the command, and jl keeps running on SIGINT
+// until the command exits.
+func runCommand(args []string, input *inputFlags, printer jl.EntryPrinter) error {
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = os.Stdin
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+	// Both streams are printed concurrently, so that neither blocks the command while the other is being read. The
+	// printer is flushed once both are done, rather than by each parser.
+	shared := &sharedPrinter{printer: printer}
+	stdoutParser, err := input.newParser(stdout, shared)
+	if err != nil {
+		return err
+	}
+	stderrParser, err := input.newParser(stderr, shared)
+	if err != nil {
+		return err
+	}
+	stderrParser.Source = stderrSource
+
+	// The command is in jl's process group, so an interrupt from the terminal already reaches it, and forwarding it
+	// would deliver it twice. jl keeps running to print what the command writes until it exits. SIGTERM is usually sent
+	// to jl alone, so it is forwarded.
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				if sig == syscall.SIGTERM {
+					cmd.Process.Signal(sig)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	var stdoutErr, stderrErr error
+	consume := func(parser *jl.Parser, err *error) {
+		defer wg.Done()
+		*err = parser.Consume()
+	}
+	wg.Add(2)
+	go consume(stdoutParser, &stdoutErr)
+	go consume(stderrParser, &stderrErr)
+	wg.Wait()
+	shared.flush()
+
+	err = cmd.Wait()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+			return exitCode(128 + int(status.Signal()))
+		}
+		return exitCode(exitErr.ExitCode())
+	}
+	if err != nil {
+		return err
+	}
+	if stdoutErr != nil {
+		return stdoutErr
+	}
+	return stderrErr
+}
+
+// sharedPrinter passes entries to a printer that is shared by multiple parsers. It deliberately does not implement
+// jl.Flusher, so that the parsers do not flush the printer as each of them finishes.
+type sharedPrinter struct {
+	printer jl.EntryPrinter
+}
+
+func (p *sharedPrinter) Print(entry *jl.Entry) {
+	p.printer.Print(entry)
+}
+
+// flush flushes the printer.
+func (p *sharedPrinter) flush() {
+	jl.Flush(p.printer)
+}
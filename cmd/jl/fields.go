@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/mightyguava/jl"
+	"os"
+)
+
+// runFields scans the logs and prints the fields found, with statistics of their values.
+func runFields(args []string) error {
+	fs := flag.NewFlagSet(os.Args[0]+" fields", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Printf(`Usage of %s fields:
+
+    %s fields [filename]
+
+Lists every field of the JSON logs, including nested fields in dotted notation, with their types, the percentage of
+entries that have them, the number of distinct values, and the most common values. If [filename] is omitted, it reads
+from standard input.
+
+`, os.Args[0], os.Args[0])
+		fs.PrintDefaults()
+	}
+	colorFlags := addColorFlags(fs)
+	filterFlags := addFilterFlags(fs)
+	inputFlags := addInputFlags(fs)
+	top := fs.Int("top", 3, "Number of most common values to print for each field")
+	maxCardinality := fs.Int("max-distinct", jl.DefaultMaxCardinality, "Stop counting the values of a field after this many distinct values")
+	fs.Parse(args)
+
+	disableColor, _, _, err := colorFlags.resolve()
+	if err != nil {
+		return err
+	}
+	grep, err := filterFlags.grepFilter()
+	if err != nil {
+		return err
+	}
+	fp := jl.NewFieldsPrinter(os.Stdout)
+	fp.Scanner.MaxCardinality = *maxCardinality
+	fp.Top = *top
+	fp.DisableColor = disableColor
+	printer, err := filterFlags.wrap(os.Stdout, fp, grep, 0, 0)
+	if err != nil {
+		return err
+	}
+
+	return consumeInput(fs, inputFlags, printer)
+}
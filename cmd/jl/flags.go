@@ -0,0 +1,422 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-isatty"
+	"github.com/mightyguava/jl"
+)
+
+// colorFlags control whether and how output is colored.
+type colorFlags struct {
+	color *string
+	theme *string
+}
+
+func addColorFlags(fs *flag.FlagSet) *colorFlags {
+	return &colorFlags{
+		color: fs.String("color", "auto", `Sets the color mode. The options are "auto", "yes", and "no". "auto" disables color if stdout is not a tty or NO_COLOR is set`),
+		theme: fs.String("theme", "dark", fmt.Sprintf("Color theme. The options are %q", jl.ThemeNames())),
+	}
+}
+
+// resolve returns whether color is disabled, the color mode of the terminal and the theme.
+func (f *colorFlags) resolve() (bool, jl.ColorMode, *jl.Theme, error) {
+	disableColor := false
+	colorMode := jl.DetectColorMode()
+	switch *f.color {
+	case "auto":
+		if !isatty.IsTerminal(os.Stdout.Fd()) || colorMode == jl.ColorModeNone {
+			disableColor = true
+		}
+	case "yes":
+		disableColor = false
+		if colorMode == jl.ColorModeNone {
+			colorMode = jl.ColorMode16
+		}
+	case "no":
+		disableColor = true
+	default:
+		return false, 0, nil, fmt.Errorf("invalid -color=%s", *f.color)
+	}
+	theme, ok := jl.Themes[*f.theme]
+	if !ok {
+		return false, 0, nil, fmt.Errorf("invalid -theme=%s", *f.theme)
+	}
+	return disableColor, colorMode, theme, nil
+}
+
+// inputFlags control how lines of input are decoded.
+type inputFlags struct {
+	input               *string
+	patterns            stringsFlag
+	continuation        *bool
+	continuationPattern *string
+	decodeJSON          *string
+}
+
+func addInputFlags(fs *flag.FlagSet) *inputFlags {
+	f := &inputFlags{
+		input: fs.String("input", "json", fmt.Sprintf(`Format of the input. The options are %q. "regex" decodes lines with -pattern, and "auto" detects the format of each line`, jl.DecoderNames())),
+	}
+	f.continuation = fs.Bool("continuation", false, "Attach lines that are not decoded, like stack traces, to the entry before them instead of printing them on their own")
+	f.continuationPattern = fs.String("continuation-pattern", "", `Only attach lines matching this regular expression with -continuation, or "default" for lines that look like stack traces. Implies -continuation`)
+	f.decodeJSON = fs.String("decode-json", "", `Comma-separated list of fields holding JSON encoded as strings to decode, like message or payload.data, or "auto" to decode any such field`)
+	fs.Var(&f.patterns, "pattern", "Grok pattern, like %{INT:status} %{GREEDYDATA:message}, or regular expression with named groups that -input regex decodes lines with. May be repeated to try several patterns. Implies -input regex")
+	return f
+}
+
+// newDecoder returns a new decoder for the -input format, which decodes the JSON embedded in the -decode-json fields.
+func (f *inputFlags) newDecoder() (jl.Decoder, error) {
+	decoder, err := f.newInputDecoder()
+	if err != nil || *f.decodeJSON == "" {
+		return decoder, err
+	}
+	if *f.decodeJSON == "auto" {
+		return jl.NewEmbeddedJSONDecoder(decoder), nil
+	}
+	return jl.NewEmbeddedJSONDecoder(decoder, strings.Split(*f.decodeJSON, ",")...), nil
+}
+
+// newInputDecoder returns a new decoder for the -input format.
+func (f *inputFlags) newInputDecoder() (jl.Decoder, error) {
+	input := *f.input
+	if len(f.patterns) > 0 && input == "json" {
+		input = "regex"
+	}
+	newDecoder, ok := jl.Decoders[input]
+	if !ok {
+		return nil, fmt.Errorf("invalid -input=%s", input)
+	}
+	decoder := newDecoder()
+	if rd, ok := decoder.(*jl.RegexDecoder); ok {
+		if len(f.patterns) == 0 {
+			return nil, fmt.Errorf("-input regex requires -pattern")
+		}
+		d, err := jl.NewRegexDecoder(f.patterns...)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -pattern: %v", err)
+		}
+		rd.Patterns = d.Patterns
+	}
+	return decoder, nil
+}
+
+// newParser returns a parser that decodes the lines of r with the -input decoder, and prints them with printer.
+func (f *inputFlags) newParser(r io.Reader, printer jl.EntryPrinter) (*jl.Parser, error) {
+	decoder, err := f.newDecoder()
+	if err != nil {
+		return nil, err
+	}
+	parser := jl.NewParser(r, printer)
+	parser.Decoder = decoder
+	parser.Continuations = *f.continuation || *f.continuationPattern != ""
+	switch *f.continuationPattern {
+	case "":
+	case "default":
+		parser.ContinuationPattern = jl.DefaultContinuationPattern
+	default:
+		re, err := regexp.Compile(*f.continuationPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -continuation-pattern=%s: %v", *f.continuationPattern, err)
+		}
+		parser.ContinuationPattern = re
+	}
+	return parser, nil
+}
+
+// filterFlags select and redact entries.
+type filterFlags struct {
+	grep          *string
+	grepField     *string
+	redact        *bool
+	redactMode    *string
+	redactKeys    *string
+	redactRegexes stringsFlag
+}
+
+func addFilterFlags(fs *flag.FlagSet) *filterFlags {
+	f := &filterFlags{
+		grep:       fs.String("grep", "", "Only print entries matching this regular expression, highlighting the matches"),
+		grepField:  fs.String("grep-field", "", "Comma-separated list of fields to match -grep against, instead of the whole line"),
+		redact:     fs.Bool("redact", false, "Redact secrets and personal information, like passwords, tokens, emails, card numbers and IP addresses"),
+		redactMode: fs.String("redact-mode", "mask", `How to redact values. The options are "mask", "partial" to keep the last few characters, and "hash" to replace values with a hash so they can still be correlated`),
+		redactKeys: fs.String("redact-key", "", "Comma-separated list of additional field name patterns to redact, like *session*. Implies -redact"),
+	}
+	fs.Var(&f.redactRegexes, "redact-regex", "Additional regular expression of values to redact. May be repeated. Implies -redact")
+	return f
+}
+
+// grepFilter returns the Grep requested by the flags, or nil if none was.
+func (f *filterFlags) grepFilter() (*jl.Grep, error) {
+	if *f.grep == "" {
+		return nil, nil
+	}
+	var fields []string
+	if *f.grepField != "" {
+		fields = strings.Split(*f.grepField, ",")
+		for _, name := range fields {
+			if _, err := jl.ByPath(name); err != nil {
+				return nil, fmt.Errorf("invalid -grep-field=%s: %v", *f.grepField, err)
+			}
+		}
+	}
+	grep, err := jl.NewGrep(*f.grep, fields...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -grep=%s: %v", *f.grep, err)
+	}
+	return grep, nil
+}
+
+// redactor returns the Redactor requested by the flags, or nil if none was.
+func (f *filterFlags) redactor() (*jl.Redactor, error) {
+	if !*f.redact && *f.redactKeys == "" && len(f.redactRegexes) == 0 {
+		return nil, nil
+	}
+	redactor := jl.NewRedactor()
+	switch *f.redactMode {
+	case "mask":
+		redactor.Mode = jl.RedactMask
+	case "partial":
+		redactor.Mode = jl.RedactPartial
+	case "hash":
+		redactor.Mode = jl.RedactHash
+	default:
+		return nil, fmt.Errorf("invalid -redact-mode=%s", *f.redactMode)
+	}
+	if *f.redactKeys != "" {
+		redactor.Keys = append(redactor.Keys, strings.Split(*f.redactKeys, ",")...)
+	}
+	for _, expr := range f.redactRegexes {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -redact-regex=%s: %v", expr, err)
+		}
+		redactor.Detectors = append(redactor.Detectors, jl.Detector{Name: "custom", Pattern: re})
+	}
+	return redactor, nil
+}
+
+// wrap wraps printer so that only entries selected by grep are printed, with before and after entries of context,
+// after being redacted. Printer is returned as is if neither was requested.
+func (f *filterFlags) wrap(w io.Writer, printer jl.EntryPrinter, grep *jl.Grep, before, after int) (jl.EntryPrinter, error) {
+	if grep != nil {
+		gp := jl.NewGrepPrinter(w, printer, grep)
+		gp.Before, gp.After = before, after
+		printer = gp
+	}
+	redactor, err := f.redactor()
+	if err != nil {
+		return nil, err
+	}
+	if redactor != nil {
+		printer = jl.NewRedactPrinter(printer, redactor)
+	}
+	return printer, nil
+}
+
+// formatFlags control how entries are printed.
+type formatFlags struct {
+	*colorFlags
+	*filterFlags
+	format      *string
+	truncate    *bool
+	width       *int
+	noWrap      *bool
+	context     *int
+	before      *int
+	after       *int
+	trace       *bool
+	traceErrors *bool
+	traceIdle   *time.Duration
+	collapse    *bool
+	dedupe      *bool
+	dedupeField *string
+
+	splitBy      *string
+	outDir       *string
+	splitFormat  *string
+	maxOpenFiles *int
+
+	// split is the printer created for -split-by, if any.
+	split *jl.SplitPrinter
+}
+
+func addFormatFlags(fs *flag.FlagSet) *formatFlags {
+	f := &formatFlags{
+		format:      fs.String("format", "compact", `Formatter for logs. The options are "compact" and "logfmt"`),
+		colorFlags:  addColorFlags(fs),
+		truncate:    fs.Bool("truncate", true, "Whether to truncate strings in the compact formatter"),
+		width:       fs.Int("width", 0, "Width of the terminal, used to wrap messages in the compact formatter. Detected automatically if stdout is a terminal"),
+		noWrap:      fs.Bool("nowrap", false, "Print only the first line of each entry in the compact formatter, truncated to the terminal width"),
+		filterFlags: addFilterFlags(fs),
+		context:     fs.Int("C", 0, "Print this many entries of context before and after each -grep match"),
+		before:      fs.Int("B", 0, "Print this many entries of context before each -grep match"),
+		after:       fs.Int("A", 0, "Print this many entries of context after each -grep match"),
+		trace:       fs.Bool("trace", false, "Group entries by trace ID, indenting them by span"),
+		traceErrors: fs.Bool("trace-errors", false, "Only print traces that contain an error. Implies -trace"),
+		traceIdle:   fs.Duration("trace-idle", jl.DefaultTraceIdleTimeout, "Print a trace once no entries for it have been seen for this long"),
+		collapse:    fs.Bool("collapse", false, "Fold consecutive entries with the same message pattern into a single line with their count"),
+		dedupe:      fs.Bool("dedupe", false, "Collapse consecutive entries with the same level, logger and message into one line with a repeat counter"),
+		dedupeField: fs.String("dedupe-field", "", "Comma-separated list of fields compared by -dedupe, instead of level, logger and message. Implies -dedupe"),
+
+		splitBy:      fs.String("split-by", "", "Write entries to one file per value of this field, like thread or traceId, instead of printing them"),
+		outDir:       fs.String("out-dir", ".", "Directory that -split-by writes files to"),
+		splitFormat:  fs.String("split-format", "json", `Format of the files written by -split-by. The options are "json" for the original lines, and "formatted" for the -format formatter without color`),
+		maxOpenFiles: fs.Int("max-open-files", jl.DefaultMaxOpenFiles, "Maximum number of files -split-by keeps open at a time"),
+	}
+	return f
+}
+
+// newPrinter returns the printer requested by the flags, writing to w.
+func (f *formatFlags) newPrinter(w io.Writer) (jl.EntryPrinter, error) {
+	disableColor, colorMode, theme, err := f.resolve()
+	if err != nil {
+		return nil, err
+	}
+	if *f.format != "logfmt" && *f.format != "compact" {
+		return nil, fmt.Errorf("invalid -format=%s", *f.format)
+	}
+	grep, err := f.grepFilter()
+	if err != nil {
+		return nil, err
+	}
+	if *f.width == 0 {
+		*f.width = detectWidth()
+	}
+	newPrinter := func(w io.Writer) jl.EntryPrinter {
+		return f.newEntryPrinter(w, disableColor, colorMode, theme, grep, *f.width)
+	}
+
+	var printer jl.EntryPrinter
+	trace := *f.trace || *f.traceErrors
+	dedupe := *f.dedupe || *f.dedupeField != ""
+	switch {
+	case *f.splitBy != "" && (trace || dedupe || *f.collapse):
+		return nil, fmt.Errorf("-split-by cannot be combined with -trace, -dedupe or -collapse")
+	case *f.splitBy != "":
+		splitBy, err := jl.ByPath(*f.splitBy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -split-by=%s: %v", *f.splitBy, err)
+		}
+		sp := jl.NewSplitPrinter(*f.outDir, splitBy)
+		switch *f.splitFormat {
+		case "json":
+		case "formatted":
+			sp.Ext = ".log"
+			sp.NewPrinter = func(w io.Writer) jl.EntryPrinter {
+				return f.newEntryPrinter(w, true, colorMode, theme, nil, 0)
+			}
+		default:
+			return nil, fmt.Errorf("invalid -split-format=%s", *f.splitFormat)
+		}
+		sp.MaxOpenFiles = *f.maxOpenFiles
+		f.split = sp
+		printer = sp
+	case trace && *f.collapse:
+		return nil, fmt.Errorf("-trace cannot be combined with -collapse")
+	case trace && dedupe:
+		return nil, fmt.Errorf("-trace cannot be combined with -dedupe")
+	case *f.collapse && dedupe:
+		return nil, fmt.Errorf("-collapse cannot be combined with -dedupe")
+	case trace:
+		tp := jl.NewTracePrinter(w, newPrinter)
+		tp.DisableColor = disableColor
+		tp.ColorMode = colorMode
+		tp.ErrorsOnly = *f.traceErrors
+		tp.IdleTimeout = *f.traceIdle
+		printer = tp
+	case *f.collapse:
+		cp := jl.NewCollapsePrinter(w, newPrinter(w))
+		cp.DisableColor = disableColor
+		printer = cp
+	case dedupe:
+		dp := jl.NewDedupePrinter(w, newPrinter)
+		dp.DisableColor = disableColor
+		if *f.dedupeField != "" {
+			dp.Fields = nil
+			for _, name := range strings.Split(*f.dedupeField, ",") {
+				field, err := jl.ByPath(name)
+				if err != nil {
+					return nil, fmt.Errorf("invalid -dedupe-field=%s: %v", *f.dedupeField, err)
+				}
+				dp.Fields = append(dp.Fields, field)
+			}
+		}
+		printer = dp
+	default:
+		printer = newPrinter(w)
+	}
+
+	before, after := *f.context, *f.context
+	if *f.before > 0 {
+		before = *f.before
+	}
+	if *f.after > 0 {
+		after = *f.after
+	}
+	return f.wrap(w, printer, grep, before, after)
+}
+
+// newEntryPrinter returns the printer for the -format flag, writing to w. If grep is not nil, its matches are
+// highlighted. Messages are wrapped to width columns if it is not 0.
+func (f *formatFlags) newEntryPrinter(w io.Writer, disableColor bool, colorMode jl.ColorMode, theme *jl.Theme, grep *jl.Grep, width int) jl.EntryPrinter {
+	if *f.format == "logfmt" {
+		lp := jl.NewLogfmtPrinter(w)
+		lp.DisableColor = disableColor
+		lp.ColorMode = colorMode
+		lp.Theme = theme
+		if grep != nil {
+			lp.Highlighter = grep
+		}
+		return lp
+	}
+	cp := jl.NewCompactPrinter(w)
+	cp.DisableColor = disableColor
+	cp.ColorMode = colorMode
+	cp.FieldFormats = jl.NewCompactPrinterFieldFmt(theme)
+	cp.Width = width
+	cp.NoWrap = *f.noWrap
+	cp.DisableTruncate = !*f.truncate
+	if grep != nil {
+		cp.Highlighter = grep
+	}
+	return cp
+}
+
+// openInput opens the file named by the first argument, or returns stdin if there are no arguments.
+func openInput(fs *flag.FlagSet) (*os.File, error) {
+	if fs.NArg() == 0 {
+		return os.Stdin, nil
+	}
+	return os.Open(fs.Arg(0))
+}
+
+// detectWidth returns the width of the terminal attached to stdout, falling back to the COLUMNS environment variable.
+// It returns 0 if the width is unknown.
+func detectWidth() int {
+	if w := terminalWidth(os.Stdout); w > 0 {
+		return w
+	}
+	w, _ := strconv.Atoi(os.Getenv("COLUMNS"))
+	return w
+}
+
+// stringsFlag is a flag that may be repeated to build a list of strings.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
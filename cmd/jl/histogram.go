@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/mightyguava/jl"
+	"os"
+)
+
+// histogramLabelWidth is the number of columns taken up by everything but the bar in a line of the histogram.
+const histogramLabelWidth = 60
+
+// runHistogram prints a histogram of the number of entries over time.
+func runHistogram(args []string) error {
+	fs := flag.NewFlagSet(os.Args[0]+" histogram", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Printf(`Usage of %s histogram:
+
+    %s histogram [filename]
+
+Prints a bar chart of the number of entries per time bucket, stacked by level, followed by sparklines of all entries
+and of errors. Buckets are printed once -window later buckets have started, so the histogram can be followed on a
+stream while entries that arrive slightly out of order are still counted. If [filename] is omitted, it reads from
+standard input.
+
+`, os.Args[0], os.Args[0])
+		fs.PrintDefaults()
+	}
+	colorFlags := addColorFlags(fs)
+	filterFlags := addFilterFlags(fs)
+	inputFlags := addInputFlags(fs)
+	bucket := fs.Duration("bucket", jl.DefaultHistogramBucket, "Length of time counted by each bar")
+	width := fs.Int("width", 0, "Width of the longest bar. Fits the terminal by default")
+	window := fs.Int("window", jl.DefaultHistogramWindow, "Number of most recent buckets kept open for entries that arrive out of order")
+	fs.Parse(args)
+
+	disableColor, colorMode, theme, err := colorFlags.resolve()
+	if err != nil {
+		return err
+	}
+	if *bucket <= 0 {
+		return fmt.Errorf("invalid -bucket=%s", *bucket)
+	}
+	grep, err := filterFlags.grepFilter()
+	if err != nil {
+		return err
+	}
+	hp := jl.NewHistogramPrinter(os.Stdout)
+	hp.Bucket = *bucket
+	hp.Window = *window
+	hp.DisableColor = disableColor
+	hp.ColorMode = colorMode
+	hp.Theme = theme
+	if *width > 0 {
+		hp.Width = *width
+	} else if w := detectWidth() - histogramLabelWidth; w > 10 {
+		hp.Width = w
+	}
+	printer, err := filterFlags.wrap(os.Stdout, hp, grep, 0, 0)
+	if err != nil {
+		return err
+	}
+
+	return consumeInput(fs, inputFlags, printer)
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"github.com/mightyguava/jl"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// maxSyslogMessageSize is the size of the largest syslog message that can be received over UDP.
+const maxSyslogMessageSize = 64 * 1024
+
+// runListen receives logs over the network, and prints them.
+func runListen(args []string) error {
+	fs := flag.NewFlagSet(os.Args[0]+" listen", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Printf(`Usage of %s listen:
+
+    %s listen -syslog udp://:5514 -tcp :5170
+
+Receives syslog messages, in the RFC 5424 or RFC 3164 formats, and newline-delimited JSON over TCP, and prints them
+tagged with the address they came from. Syslog messages whose text is a JSON object are printed as that object.
+
+`, os.Args[0], os.Args[0])
+		fs.PrintDefaults()
+	}
+	syslogAddr := fs.String("syslog", "", `Address to receive syslog messages on, as udp://host:port or tcp://host:port`)
+	tcpAddr := fs.String("tcp", "", "Address to receive newline-delimited logs on, decoded with -input, as host:port")
+	formatFlags := addFormatFlags(fs)
+	inputFlags := addInputFlags(fs)
+	fs.Parse(args)
+	if *syslogAddr == "" && *tcpAddr == "" {
+		return fmt.Errorf("at least one of -syslog or -tcp is required")
+	}
+
+	printer, err := formatFlags.newPrinter(os.Stdout)
+	if err != nil {
+		return err
+	}
+	// Check the input flags before listening, since parsers are only created as connections are accepted.
+	if _, err := inputFlags.newParser(nil, printer); err != nil {
+		return err
+	}
+	readStream := func(r io.Reader, source string, printer jl.EntryPrinter) {
+		parser, _ := inputFlags.newParser(r, printer)
+		parser.Source = source
+		parser.Consume()
+	}
+	shared := &sharedPrinter{printer: printer}
+	errs := make(chan error, 2)
+	if *syslogAddr != "" {
+		network, addr := splitNetworkAddr(*syslogAddr, "udp")
+		switch network {
+		case "udp":
+			conn, err := net.ListenPacket(network, addr)
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+			go func() { errs <- serveSyslogUDP(conn, shared) }()
+		case "tcp":
+			l, err := net.Listen(network, addr)
+			if err != nil {
+				return err
+			}
+			defer l.Close()
+			go func() { errs <- serveTCP(l, shared, readSyslogStream) }()
+		default:
+			return fmt.Errorf("invalid -syslog=%s", *syslogAddr)
+		}
+	}
+	if *tcpAddr != "" {
+		l, err := net.Listen("tcp", *tcpAddr)
+		if err != nil {
+			return err
+		}
+		defer l.Close()
+		go func() { errs <- serveTCP(l, shared, readStream) }()
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	select {
+	case err = <-errs:
+	case <-signals:
+	}
+	shared.flush()
+	return err
+}
+
+// splitNetworkAddr splits an address like udp://:5514 into its network and host:port. If it has no scheme, the
+// network is defaultNetwork.
+func splitNetworkAddr(s, defaultNetwork string) (network, addr string) {
+	if i := strings.Index(s, "://"); i >= 0 {
+		return s[:i], s[i+3:]
+	}
+	return defaultNetwork, s
+}
+
+// serveSyslogUDP prints the syslog messages received on conn, one per packet.
+func serveSyslogUDP(conn net.PacketConn, printer jl.EntryPrinter) error {
+	buf := make([]byte, maxSyslogMessageSize)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			return err
+		}
+		entry := syslogEntry(append([]byte(nil), buf[:n]...))
+		entry.Source = addr.String()
+		printer.Print(entry)
+	}
+}
+
+// serveTCP accepts connections on l, and prints the entries read from each with read.
+func serveTCP(l net.Listener, printer jl.EntryPrinter, read func(r io.Reader, source string, printer jl.EntryPrinter)) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			read(conn, conn.RemoteAddr().String(), printer)
+		}()
+	}
+}
+
+// readSyslogStream prints the syslog messages read from r. Messages may be framed with octet counting or terminated by
+// newlines, as described in RFC 6587.
+func readSyslogStream(r io.Reader, source string, printer jl.EntryPrinter) {
+	br := bufio.NewReader(r)
+	for {
+		msg, err := readSyslogFrame(br)
+		if len(msg) > 0 {
+			entry := syslogEntry(msg)
+			entry.Source = source
+			printer.Print(entry)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func readSyslogFrame(br *bufio.Reader) ([]byte, error) {
+	b, err := br.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+	if b[0] >= '1' && b[0] <= '9' {
+		length, err := br.ReadString(' ')
+		if err != nil {
+			return nil, err
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(length))
+		if err != nil || n > maxSyslogMessageSize {
+			return nil, fmt.Errorf("invalid syslog frame length %q", length)
+		}
+		msg := make([]byte, n)
+		_, err = io.ReadFull(br, msg)
+		return msg, err
+	}
+	msg, err := br.ReadBytes('\n')
+	return []byte(strings.TrimRight(string(msg), "\r\n")), err
+}
+
+// syslogEntry converts a syslog message to an entry. Messages that cannot be parsed are printed as is.
+func syslogEntry(msg []byte) *jl.Entry {
+	if m, ok := jl.ParseSyslog(msg); ok {
+		return m.Entry()
+	}
+	return jl.NewEntry(msg)
+}
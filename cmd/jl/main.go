@@ -3,70 +3,94 @@ package main
 import (
 	"flag"
 	"fmt"
-	"github.com/mattn/go-isatty"
 	"github.com/mightyguava/jl"
 	"os"
 )
 
+// commands are the subcommands of jl, by name.
+var commands = map[string]func(args []string) error{
+	"fields":    runFields,
+	"histogram": runHistogram,
+	"listen":    runListen,
+	"patterns":  runPatterns,
+	"serve":     runServe,
+}
+
 func main() {
 	if err := run(); err != nil {
+		if code, ok := err.(exitCode); ok {
+			os.Exit(int(code))
+		}
 		fmt.Fprintf(os.Stderr, "%v\n", err)
 		os.Exit(1)
 	}
 }
 
 func run() error {
-	flag.Usage = func() {
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			return command(os.Args[2:])
+		}
+	}
+	return runPrint(os.Args[1:])
+}
+
+func runPrint(args []string) error {
+	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	fs.Usage = func() {
 		fmt.Printf(`Usage of %s:
 
     %s [filename]
+    %s -- command [args...]
+    %s patterns [filename]
+    %s fields [filename]
+    %s histogram [filename]
+    %s listen -syslog udp://:5514 -tcp :5170
+    %s serve [-http localhost:9880]
 
-If [filename] is omitted, it reads from standard input.
+If [filename] is omitted, it reads from standard input. With "--", it runs the command and reads what it writes to
+stdout and stderr, tagging lines from stderr, and exits with the command's exit code.
 
-`, os.Args[0], os.Args[0])
-		flag.PrintDefaults()
+`, os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0])
+		fs.PrintDefaults()
 	}
-	formatFlag := flag.String("format", "compact", `Formatter for logs. The options are "compact" and "logfmt"`)
-	color := flag.String("color", "auto", `Sets the color mode. The options are "auto", "yes", and "no". "auto" disables color if stdout is not a tty`)
-	truncate := flag.Bool("truncate", true, "Whether to truncate strings in the compact formatter")
-	flag.Parse()
+	formatFlags := addFormatFlags(fs)
+	inputFlags := addInputFlags(fs)
+	fs.Parse(args)
 
-	disableColor := false
-	switch *color {
-	case "auto":
-		if !isatty.IsTerminal(os.Stdout.Fd()) {
-			disableColor = true
-		}
-	case "yes":
-		disableColor = false
-	case "no":
-		disableColor = true
-	default:
-		return fmt.Errorf("invalid -color=%s", *color)
+	printer, err := formatFlags.newPrinter(os.Stdout)
+	if err != nil {
+		return err
+	}
+	if command := commandArgs(fs, args); command != nil {
+		err = runCommand(command, inputFlags, printer)
+	} else {
+		err = consumeInput(fs, inputFlags, printer)
 	}
+	if err == nil && formatFlags.split != nil {
+		err = formatFlags.split.Err()
+	}
+	return err
+}
 
-	var printer jl.EntryPrinter
-	switch *formatFlag {
-	case "logfmt":
-		lp := jl.NewLogfmtPrinter(os.Stdout)
-		lp.DisableColor = disableColor
-		printer = lp
-	case "compact":
-		cp := jl.NewCompactPrinter(os.Stdout)
-		cp.DisableColor = disableColor
-		cp.DisableTruncate = !*truncate
-		printer = cp
+// consumeInput prints the entries of the input named by the arguments with printer.
+func consumeInput(fs *flag.FlagSet, input *inputFlags, printer jl.EntryPrinter) error {
+	inFile, err := openInput(fs)
+	if err != nil {
+		return err
 	}
+	defer inFile.Close()
+	parser, err := input.newParser(inFile, printer)
+	if err != nil {
+		return err
+	}
+	return parser.Consume()
+}
 
-	fileArg := flag.Arg(0)
-	inFile := os.Stdin
-	if fileArg != "" {
-		f, err := os.Open(fileArg)
-		if err != nil {
-			return err
-		}
-		defer f.Close()
-		inFile = f
+// commandArgs returns the command and arguments following a "--" terminator in args, or nil if there are none.
+func commandArgs(fs *flag.FlagSet, args []string) []string {
+	if n := len(args) - fs.NArg(); fs.NArg() > 0 && n > 0 && args[n-1] == "--" {
+		return fs.Args()
 	}
-	return jl.NewParser(inFile, printer).Consume()
+	return nil
 }
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/mightyguava/jl"
+	"os"
+)
+
+// runPatterns clusters log messages into patterns, and prints the most common ones.
+func runPatterns(args []string) error {
+	fs := flag.NewFlagSet(os.Args[0]+" patterns", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Printf(`Usage of %s patterns:
+
+    %s patterns [filename]
+
+Clusters log messages into patterns, masking numbers, IDs and quoted values, and prints each pattern with its count,
+levels, first and last timestamps, and an example message. If [filename] is omitted, it reads from standard input.
+
+`, os.Args[0], os.Args[0])
+		fs.PrintDefaults()
+	}
+	colorFlags := addColorFlags(fs)
+	filterFlags := addFilterFlags(fs)
+	inputFlags := addInputFlags(fs)
+	similarity := fs.Float64("similarity", jl.DefaultPatternSimilarity, "Fraction of words two messages must have in common to share a pattern")
+	limit := fs.Int("limit", 0, "Print only the most common patterns. 0 prints all patterns")
+	fs.Parse(args)
+
+	disableColor, _, _, err := colorFlags.resolve()
+	if err != nil {
+		return err
+	}
+	grep, err := filterFlags.grepFilter()
+	if err != nil {
+		return err
+	}
+	pp := jl.NewPatternPrinter(os.Stdout)
+	pp.Miner.Similarity = *similarity
+	pp.Limit = *limit
+	pp.DisableColor = disableColor
+	printer, err := filterFlags.wrap(os.Stdout, pp, grep, 0, 0)
+	if err != nil {
+		return err
+	}
+
+	return consumeInput(fs, inputFlags, printer)
+}
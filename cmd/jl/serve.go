@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/mightyguava/jl"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// runServe receives logs over HTTP, and prints them.
+func runServe(args []string) error {
+	fs := flag.NewFlagSet(os.Args[0]+" serve", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Printf(`Usage of %s serve:
+
+    %s serve [-http localhost:9880]
+
+Receives logs over HTTP in the formats of common log backends, and prints them tagged with the address they came from,
+so that jl can stand in for a log backend during development. It accepts Loki pushes in JSON at /loki/api/v1/push,
+Elasticsearch bulk requests at /_bulk, and newline-delimited JSON POSTed to any other path. Pushed lines are decoded
+with -input, so lines in other formats may be POSTed too. It listens on localhost by default. Since anyone who can
+reach it can send it logs, only listen on other interfaces, like with -http :9880, on trusted networks.
+
+`, os.Args[0], os.Args[0])
+		fs.PrintDefaults()
+	}
+	addr := fs.String("http", "localhost:9880", "Address to receive logs on, as host:port. Use :9880 to receive logs from other hosts")
+	formatFlags := addFormatFlags(fs)
+	inputFlags := addInputFlags(fs)
+	fs.Parse(args)
+
+	if *inputFlags.continuation || *inputFlags.continuationPattern != "" {
+		return fmt.Errorf("-continuation is not supported by serve")
+	}
+	decoder, err := inputFlags.newDecoder()
+	if err != nil {
+		return err
+	}
+	printer, err := formatFlags.newPrinter(os.Stdout)
+	if err != nil {
+		return err
+	}
+	shared := &sharedPrinter{printer: printer}
+	l, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+	errs := make(chan error, 1)
+	handler := jl.NewIngestHandler(shared)
+	handler.Decoder = decoder
+	go func() { errs <- http.Serve(l, handler) }()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	select {
+	case err = <-errs:
+	case <-signals:
+		l.Close()
+	}
+	shared.flush()
+	return err
+}
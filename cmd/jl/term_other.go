@@ -0,0 +1,13 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package main
+
+import (
+	"os"
+)
+
+// terminalWidth returns 0, since detecting the terminal width is not supported on this platform.
+func terminalWidth(f *os.File) int {
+	return 0
+}
@@ -1,21 +1,29 @@
 package jl
 
 import (
+	"hash/fnv"
 	"strings"
+	"sync"
 )
 
 type sequentialColorizer struct {
-	assigned map[string]Color
+	mu       sync.Mutex
+	assigned map[string]Style
 	seq      int
-	colors   []Color
+	styles   []Style
 }
 
 // ColorSequence assigns colors to inputs sequentially. Once an input is seen and assigned a color, future iputs with
 // the same value will always be assigned the same color.
 func ColorSequence(colors []Color) *sequentialColorizer {
+	return StyleSequence(colorStyles(colors))
+}
+
+// StyleSequence is like ColorSequence, but assigns styles instead of colors.
+func StyleSequence(styles []Style) *sequentialColorizer {
 	return &sequentialColorizer{
-		assigned: make(map[string]Color),
-		colors:   colors,
+		assigned: make(map[string]Style),
+		styles:   styles,
 	}
 }
 
@@ -23,22 +31,29 @@ func (a *sequentialColorizer) Transform(ctx *Context, input string) string {
 	if ctx.DisableColor {
 		return input
 	}
-	if color, ok := a.assigned[ctx.Original]; ok {
-		return ColorText(color, input)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if style, ok := a.assigned[ctx.Original]; ok {
+		return ctx.render(style, input)
 	}
-	color := a.colors[a.seq%len(AllColors)]
+	style := a.styles[a.seq%len(a.styles)]
 	a.seq++
-	a.assigned[ctx.Original] = color
-	return ColorText(color, input)
+	a.assigned[ctx.Original] = style
+	return ctx.render(style, input)
 }
 
 type mappingColorizer struct {
-	mapping map[string]Color
+	mapping map[string]Style
 }
 
 // ColorMap assigns colors by mapping the original, pre-transform field value to a color based on a pre-defined mapping.
 func ColorMap(mapping map[string]Color) *mappingColorizer {
-	lowered := make(map[string]Color, len(mapping))
+	return StyleMap(colorStyleMap(mapping))
+}
+
+// StyleMap is like ColorMap, but assigns styles instead of colors.
+func StyleMap(mapping map[string]Style) *mappingColorizer {
+	lowered := make(map[string]Style, len(mapping))
 	for k, v := range mapping {
 		lowered[strings.ToLower(k)] = v
 	}
@@ -49,8 +64,76 @@ func (c *mappingColorizer) Transform(ctx *Context, input string) string {
 	if ctx.DisableColor {
 		return input
 	}
-	if color, ok := c.mapping[strings.ToLower(ctx.Original)]; ok {
-		return ColorText(color, input)
+	if style, ok := c.mapping[strings.ToLower(ctx.Original)]; ok {
+		return ctx.render(style, input)
 	}
 	return input
 }
+
+type hashColorizer struct {
+	mu     sync.Mutex
+	styles []Style
+	// owners maps each style index to the value currently assigned to it, or "" if it is free.
+	owners []string
+	// recent holds the values currently assigned a style, least recently seen first.
+	recent []string
+}
+
+// ColorHash assigns colors by hashing the original, pre-transform field value, so that a value is assigned the same
+// color across runs and processes. To keep values that are seen close together apart, a value whose color is taken by
+// one of the len(colors) most recently seen values is assigned the next free color instead.
+func ColorHash(colors []Color) *hashColorizer {
+	return StyleHash(colorStyles(colors))
+}
+
+// StyleHash is like ColorHash, but assigns styles instead of colors.
+func StyleHash(styles []Style) *hashColorizer {
+	return &hashColorizer{
+		styles: styles,
+		owners: make([]string, len(styles)),
+	}
+}
+
+func (c *hashColorizer) Transform(ctx *Context, input string) string {
+	if ctx.DisableColor {
+		return input
+	}
+	c.mu.Lock()
+	idx := c.assign(ctx.Original)
+	c.mu.Unlock()
+	return ctx.render(c.styles[idx], input)
+}
+
+// assign returns the index of the style assigned to the value, assigning one if necessary.
+func (c *hashColorizer) assign(value string) int {
+	for i, v := range c.recent {
+		if v == value {
+			copy(c.recent[i:], c.recent[i+1:])
+			c.recent[len(c.recent)-1] = value
+			return c.owner(value)
+		}
+	}
+	if len(c.recent) == len(c.styles) {
+		evicted := c.recent[0]
+		c.recent = c.recent[1:]
+		c.owners[c.owner(evicted)] = ""
+	}
+	h := fnv.New32a()
+	h.Write([]byte(value))
+	idx := int(h.Sum32() % uint32(len(c.styles)))
+	for c.owners[idx] != "" {
+		idx = (idx + 1) % len(c.styles)
+	}
+	c.owners[idx] = value
+	c.recent = append(c.recent, value)
+	return idx
+}
+
+func (c *hashColorizer) owner(value string) int {
+	for i, v := range c.owners {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
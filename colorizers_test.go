@@ -0,0 +1,57 @@
+package jl
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColorHash_Stable(t *testing.T) {
+	first := ColorHash(AllColors)
+	second := ColorHash(AllColors)
+	// Values seen in a different order by another colorizer are still assigned the same colors.
+	values := []string{"repair-worker-1", "repair-worker-2", "truck-manager"}
+	for i := range values {
+		first.assign(values[i])
+		second.assign(values[len(values)-1-i])
+	}
+	for _, v := range values {
+		assert.Equal(t, first.Transform(&Context{Original: v}, v), second.Transform(&Context{Original: v}, v))
+	}
+}
+
+func TestColorHash_AvoidsCollisions(t *testing.T) {
+	c := ColorHash([]Color{Red, Green, Blue})
+	assigned := map[int]string{}
+	for i := 0; i < 3; i++ {
+		v := fmt.Sprintf("value-%d", i)
+		idx := c.assign(v)
+		assert.NotContains(t, assigned, idx, "%s was assigned the same color as %s", v, assigned[idx])
+		assigned[idx] = v
+	}
+	// Seeing a fourth value evicts the least recently seen one, freeing its color.
+	c.assign("value-1")
+	c.assign("value-2")
+	idx := c.assign("value-3")
+	assert.Equal(t, "value-0", assigned[idx])
+}
+
+func TestColorizers_Concurrent(t *testing.T) {
+	colorizers := []Transformer{ColorSequence(AllColors), ColorHash(AllColors), ColorMap(LevelColors)}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				v := fmt.Sprintf("value-%d", (i+j)%20)
+				for _, c := range colorizers {
+					c.Transform(&Context{Original: v}, v)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
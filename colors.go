@@ -1,9 +1,14 @@
 package jl
 
 import (
-	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
 )
 
+// Color is a terminal color. The constants below are the 16 standard ANSI colors. Colors from the 256 color palette
+// and 24-bit colors can be created with Color256 and RGB. The zero value means no color.
 type Color int
 
 // Foreground text colors
@@ -30,7 +35,10 @@ const (
 	HiWhite
 )
 
-// AllColors is the set of colors used by default by DefaultCompactFieldFmts for ColorSequence.
+// AllColors is the set of 16 standard colors, other than black and red, that the Palette of DarkTheme is made of.
+//
+// Deprecated: The printers color values with the Palette of their Theme, so changing AllColors has no effect on them.
+// Set the Theme of the printer, or change DefaultTheme, instead.
 var AllColors = []Color{
 	// Skipping black because it's invisible on dark terminal backgrounds.
 	// Skipping red because it's too prominent and means error
@@ -50,12 +58,235 @@ var AllColors = []Color{
 	HiWhite,
 }
 
+const (
+	colorIndexed = 1 << 24
+	colorRGB     = 2 << 24
+)
+
+// Color256 returns color n of the 256 color palette supported by most terminals.
+func Color256(n uint8) Color {
+	return Color(colorIndexed | int(n))
+}
+
+// RGB returns a 24-bit "truecolor" color.
+func RGB(r, g, b uint8) Color {
+	return Color(colorRGB | int(r)<<16 | int(g)<<8 | int(b))
+}
+
+// basicRGB holds the approximate RGB values of the 16 standard colors, used to pick the closest standard color when
+// downgrading. The first 8 are Black through White, the rest HiBlack through HiWhite.
+var basicRGB = [16][3]int{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0}, {0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0}, {92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+func basicColor(i int) Color {
+	if i < 8 {
+		return Black + Color(i)
+	}
+	return HiBlack + Color(i-8)
+}
+
+// rgb returns the red, green and blue components of a 256 color or 24-bit color.
+func (c Color) rgb() (int, int, int) {
+	if c&colorRGB != 0 {
+		return int(c>>16) & 0xff, int(c>>8) & 0xff, int(c) & 0xff
+	}
+	n := int(c) & 0xff
+	switch {
+	case n < 16:
+		v := basicRGB[n]
+		return v[0], v[1], v[2]
+	case n < 232:
+		n -= 16
+		level := func(v int) int {
+			if v == 0 {
+				return 0
+			}
+			return 55 + v*40
+		}
+		return level(n / 36), level(n / 6 % 6), level(n % 6)
+	default:
+		v := 8 + (n-232)*10
+		return v, v, v
+	}
+}
+
+// downgrade converts the color to the closest color that can be displayed in the requested mode.
+func (c Color) downgrade(mode ColorMode) Color {
+	if c < colorIndexed || mode == ColorModeTrueColor {
+		return c
+	}
+	if c&colorIndexed != 0 && mode == ColorMode256 {
+		return c
+	}
+	if c&colorIndexed != 0 && c&0xff < 16 {
+		return basicColor(int(c) & 0xff)
+	}
+	r, g, b := c.rgb()
+	if mode == ColorMode256 {
+		return Color256(rgbTo256(r, g, b))
+	}
+	best, bestDist := 0, -1
+	for i, v := range basicRGB {
+		dist := sq(v[0]-r) + sq(v[1]-g) + sq(v[2]-b)
+		if bestDist < 0 || dist < bestDist {
+			best, bestDist = i, dist
+		}
+	}
+	return basicColor(best)
+}
+
+// rgbTo256 returns the index of the closest color in the 6x6x6 color cube or the grayscale ramp of the 256 color
+// palette.
+func rgbTo256(r, g, b int) uint8 {
+	cube := func(v int) int {
+		switch {
+		case v < 48:
+			return 0
+		case v < 115:
+			return 1
+		default:
+			return (v - 35) / 40
+		}
+	}
+	cr, cg, cb := cube(r), cube(g), cube(b)
+	cubeColor := Color256(uint8(16 + 36*cr + 6*cg + cb))
+	gray := (r + g + b) / 3
+	grayIdx := (gray - 3) / 10
+	if grayIdx < 0 {
+		grayIdx = 0
+	} else if grayIdx > 23 {
+		grayIdx = 23
+	}
+	grayColor := Color256(uint8(232 + grayIdx))
+	distance := func(c Color) int {
+		cr, cg, cb := c.rgb()
+		return sq(cr-r) + sq(cg-g) + sq(cb-b)
+	}
+	if distance(grayColor) < distance(cubeColor) {
+		return uint8(grayColor & 0xff)
+	}
+	return uint8(cubeColor & 0xff)
+}
+
+func sq(v int) int {
+	return v * v
+}
+
+// sgr returns the SGR parameters that select the color as the foreground, or background if bg is set.
+func (c Color) sgr(mode ColorMode, bg bool) string {
+	if c == 0 {
+		return ""
+	}
+	c = c.downgrade(mode)
+	prefix := "38"
+	if bg {
+		prefix = "48"
+	}
+	switch {
+	case c&colorRGB != 0:
+		r, g, b := c.rgb()
+		return prefix + ";2;" + strconv.Itoa(r) + ";" + strconv.Itoa(g) + ";" + strconv.Itoa(b)
+	case c&colorIndexed != 0:
+		return prefix + ";5;" + strconv.Itoa(int(c)&0xff)
+	case bg:
+		return strconv.Itoa(int(c) + 10)
+	default:
+		return strconv.Itoa(int(c))
+	}
+}
+
+// ColorMode is the set of colors a terminal can display. Colors outside the set are replaced with the closest color
+// inside of it.
+type ColorMode int
+
+const (
+	// ColorModeTrueColor displays all colors as requested, including 24-bit colors.
+	ColorModeTrueColor ColorMode = iota
+	// ColorMode256 displays colors from the 256 color palette.
+	ColorMode256
+	// ColorMode16 displays only the 16 standard ANSI colors.
+	ColorMode16
+	// ColorModeNone disables colors and text styles.
+	ColorModeNone
+)
+
+// DetectColorMode guesses the color support of the terminal from the environment. It honors the NO_COLOR convention
+// (https://no-color.org), then looks at COLORTERM and TERM.
+func DetectColorMode() ColorMode {
+	if os.Getenv("NO_COLOR") != "" {
+		return ColorModeNone
+	}
+	switch strings.ToLower(os.Getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return ColorModeTrueColor
+	}
+	term := strings.ToLower(os.Getenv("TERM"))
+	switch {
+	case term == "dumb":
+		return ColorModeNone
+	case strings.Contains(term, "truecolor"), strings.Contains(term, "24bit"), strings.Contains(term, "direct"):
+		return ColorModeTrueColor
+	case strings.Contains(term, "256color"):
+		return ColorMode256
+	}
+	return ColorMode16
+}
+
+// Style describes how text is displayed in the terminal: its foreground and background colors, and attributes.
+type Style struct {
+	Fg        Color
+	Bg        Color
+	Bold      bool
+	Dim       bool
+	Italic    bool
+	Underline bool
+}
+
+// Render wraps text with the ANSI escape codes for the style, downgrading colors to the requested mode.
+func (s Style) Render(mode ColorMode, text string) string {
+	if mode == ColorModeNone {
+		return text
+	}
+	var params []string
+	for _, attr := range []struct {
+		set  bool
+		code string
+	}{{s.Bold, "1"}, {s.Dim, "2"}, {s.Italic, "3"}, {s.Underline, "4"}} {
+		if attr.set {
+			params = append(params, attr.code)
+		}
+	}
+	if fg := s.Fg.sgr(mode, false); fg != "" {
+		params = append(params, fg)
+	}
+	if bg := s.Bg.sgr(mode, true); bg != "" {
+		params = append(params, bg)
+	}
+	if len(params) == 0 {
+		return text
+	}
+	return "\x1b[" + strings.Join(params, ";") + "m" + text + "\x1b[0m"
+}
+
 // ColorText wraps a text with ANSI escape codes to produce terminal colors.
 func ColorText(c Color, text string) string {
-	return fmt.Sprintf("\x1b[%dm%s\x1b[0m", c, text)
+	return Style{Fg: c}.Render(ColorModeTrueColor, text)
+}
+
+// ansiEscape matches ANSI SGR escape sequences, as produced by ColorText.
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// StripColor removes ANSI color escape sequences from text.
+func StripColor(text string) string {
+	return ansiEscape.ReplaceAllString(text, "")
 }
 
-// LevelColors is a mapping of log level strings to colors.
+// LevelColors is a mapping of log level strings to colors, which the Levels of DarkTheme are made of.
+//
+// Deprecated: The printers color levels with the Levels of their Theme, so changing LevelColors has no effect on them.
+// Set the Theme of the printer, or change DefaultTheme, instead.
 var LevelColors = map[string]Color{
 	"trace": White,
 	"debug": White,
@@ -0,0 +1,96 @@
+package jl
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStyle_Render(t *testing.T) {
+	tests := []struct {
+		name     string
+		style    Style
+		mode     ColorMode
+		rendered string
+	}{{
+		name:     "basic",
+		style:    Style{Fg: Green},
+		rendered: "\x1b[32mtext\x1b[0m",
+	}, {
+		name:     "attributes and background",
+		style:    Style{Fg: HiWhite, Bg: Red, Bold: true, Underline: true},
+		rendered: "\x1b[1;4;97;41mtext\x1b[0m",
+	}, {
+		name:     "truecolor",
+		style:    Style{Fg: RGB(0xdc, 0x32, 0x2f), Italic: true},
+		rendered: "\x1b[3;38;2;220;50;47mtext\x1b[0m",
+	}, {
+		name:     "truecolor downgraded to 256",
+		style:    Style{Fg: RGB(0xdc, 0x32, 0x2f)},
+		mode:     ColorMode256,
+		rendered: "\x1b[38;5;166mtext\x1b[0m",
+	}, {
+		name:     "gray downgraded to 256",
+		style:    Style{Bg: RGB(0x80, 0x80, 0x80)},
+		mode:     ColorMode256,
+		rendered: "\x1b[48;5;244mtext\x1b[0m",
+	}, {
+		name:     "truecolor downgraded to 16",
+		style:    Style{Fg: RGB(0xdc, 0x32, 0x2f)},
+		mode:     ColorMode16,
+		rendered: "\x1b[31mtext\x1b[0m",
+	}, {
+		name:     "256 downgraded to 16",
+		style:    Style{Fg: Color256(28), Dim: true},
+		mode:     ColorMode16,
+		rendered: "\x1b[2;32mtext\x1b[0m",
+	}, {
+		name:     "no color",
+		style:    Style{Fg: Green, Bold: true},
+		mode:     ColorModeNone,
+		rendered: "text",
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.rendered, test.style.Render(test.mode, "text"))
+		})
+	}
+}
+
+func TestDetectColorMode(t *testing.T) {
+	tests := []struct {
+		name      string
+		noColor   string
+		colorTerm string
+		term      string
+		mode      ColorMode
+	}{
+		{name: "default", term: "xterm", mode: ColorMode16},
+		{name: "256color", term: "xterm-256color", mode: ColorMode256},
+		{name: "colorterm", colorTerm: "truecolor", term: "xterm-256color", mode: ColorModeTrueColor},
+		{name: "dumb", term: "dumb", mode: ColorModeNone},
+		{name: "no color", noColor: "1", colorTerm: "truecolor", mode: ColorModeNone},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			defer setenv("NO_COLOR", test.noColor)()
+			defer setenv("COLORTERM", test.colorTerm)()
+			defer setenv("TERM", test.term)()
+			assert.Equal(t, test.mode, DetectColorMode())
+		})
+	}
+}
+
+// setenv sets an environment variable and returns a function that restores its previous value.
+func setenv(key, value string) func() {
+	prev, ok := os.LookupEnv(key)
+	os.Setenv(key, value)
+	return func() {
+		if ok {
+			os.Setenv(key, prev)
+		} else {
+			os.Unsetenv(key)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"sync"
 	"unicode"
 )
 
@@ -12,12 +13,25 @@ type CompactPrinter struct {
 	Out io.Writer
 	// Disable colors disables adding color to fields.
 	DisableColor bool
+	// ColorMode is the set of colors supported by the terminal. Colors that are not supported are downgraded to the
+	// closest supported color.
+	ColorMode ColorMode
 	// Disable truncate disables the Ellipsize and Truncate transforms.
 	DisableTruncate bool
-	// FieldFormats specifies the format the printer should use for logs. It defaults to DefaultCompactPrinterFieldFmt. Fields
-	// are formatted in the order they are provided. If a FieldFmt produces a field that does not end with a whitespace,
-	// a space character is automatically appended.
+	// FieldFormats specifies the format the printer should use for logs. It defaults to a copy of
+	// DefaultCompactPrinterFieldFmt with its own colorizers. Fields are formatted in the order they are provided. If a
+	// FieldFmt produces a field that does not end with a whitespace, a space character is automatically appended.
 	FieldFormats []FieldFmt
+	// Highlighter, if set, is applied to each formatted field after the field's own Transformers. It is used to mark
+	// search matches, see Grep.
+	Highlighter Transformer
+	// Width is the width of the terminal in columns. If set, fields with Wrap set are word wrapped to fit. If zero,
+	// lines are left for the terminal to wrap.
+	Width int
+	// NoWrap prints only the first line of each entry, truncated to Width, instead of wrapping.
+	NoWrap bool
+
+	mu sync.Mutex
 }
 
 // FieldFmt specifies a single field formatted by the CompactPrinter.
@@ -31,63 +45,123 @@ type FieldFmt struct {
 	Stringer Stringer
 	// List of transformers to run on the field found to format the field.
 	Transformers []Transformer
+	// Wrap the field to the printer's Width, indenting continuation lines to the column where the field starts.
+	Wrap bool
 }
 
 // DefaultCompactPrinterFieldFmt is a format for the CompactPrinter that tries to present logs in an easily skimmable manner
-// for most types of logs.
-var DefaultCompactPrinterFieldFmt = []FieldFmt{{
-	Name:         "level",
-	Finders:      []FieldFinder{ByNames("level", "severity", "logLevel")},
-	Transformers: []Transformer{Truncate(4), UpperCase, ColorMap(LevelColors)},
-}, {
-	Name:    "time",
-	Finders: []FieldFinder{ByNames("timestamp", "time", "ts")},
-}, {
-	Name:         "thread",
-	Transformers: []Transformer{Ellipsize(16), Format("[%s]"), RightPad(18), ColorSequence(AllColors)},
-}, {
-	Name:         "logger",
-	Finders:      []FieldFinder{ByNames("logger", "caller")},
-	Transformers: []Transformer{Ellipsize(20), Format("%s|"), LeftPad(21), ColorSequence(AllColors)},
-}, {
-	Name:         "traceId",
-	Transformers: []Transformer{Format("%s|"), ColorSequence(AllColors)},
-}, {
-	Name:    "message",
-	Finders: []FieldFinder{ByNames("message", "msg", "textPayload", "jsonPayload.message")},
-}, {
-	Name:     "errors",
-	Finders:  []FieldFinder{LogrusErrorFinder, ByNames("exceptions", "exception", "error")},
-	Stringer: ErrorStringer,
-}}
+// for most types of logs. Printers that share it also share the colors assigned to values by its colorizers.
+var DefaultCompactPrinterFieldFmt = NewCompactPrinterFieldFmt(DefaultTheme)
+
+// NewCompactPrinterFieldFmt returns the format of DefaultCompactPrinterFieldFmt, colored using the theme. Each call
+// returns new colorizers.
+func NewCompactPrinterFieldFmt(theme *Theme) []FieldFmt {
+	errorFinders := []FieldFinder{LogrusErrorFinder, ByNames("exceptions", "exception", "error")}
+	return []FieldFmt{{
+		Name:         "level",
+		Finders:      []FieldFinder{ByNames("level", "severity", "logLevel")},
+		Transformers: []Transformer{Truncate(4), UpperCase, StyleMap(theme.Levels)},
+	}, {
+		Name:    "time",
+		Finders: []FieldFinder{ByNames("timestamp", "time", "ts")},
+	}, {
+		Name:         "thread",
+		Transformers: []Transformer{Ellipsize(16), Format("[%s]"), RightPad(18), StyleHash(theme.Palette)},
+	}, {
+		Name:         "logger",
+		Finders:      []FieldFinder{ByNames("logger", "caller")},
+		Transformers: []Transformer{Ellipsize(20), Format("%s|"), LeftPad(21), StyleHash(theme.Palette)},
+	}, {
+		Name:         "traceId",
+		Transformers: []Transformer{Format("%s|"), StyleHash(theme.Palette)},
+	}, {
+		Name:    "message",
+		Finders: []FieldFinder{ByNames("message", "msg", "textPayload", "jsonPayload.message")},
+		Wrap:    true,
+	}, {
+		Name:     "errors",
+		Finders:  append([]FieldFinder{ContinuationFinder(errorFinders...)}, errorFinders...),
+		Stringer: ErrorStringer,
+	}}
+}
 
-// NewCompactPrinter allocates and returns a new compact printer.
+// NewCompactPrinter allocates and returns a new compact printer. It is safe for concurrent use, and assigns colors
+// independently of other printers.
 func NewCompactPrinter(w io.Writer) *CompactPrinter {
 	return &CompactPrinter{
 		Out:          w,
-		FieldFormats: DefaultCompactPrinterFieldFmt,
+		FieldFormats: NewCompactPrinterFieldFmt(DefaultTheme),
 	}
 }
 
 func (p *CompactPrinter) Print(entry *Entry) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	tag := sourceTag(entry, p.DisableColor, p.ColorMode)
 	if entry.Partials == nil {
-		fmt.Fprintln(p.Out, string(entry.Raw))
+		fmt.Fprintln(p.Out, tag+string(entry.Raw))
 		return
 	}
+	var line strings.Builder
+	line.WriteString(tag)
 	for i, fieldFmt := range p.FieldFormats {
 		ctx := Context{
 			DisableColor:    p.DisableColor,
 			DisableTruncate: p.DisableTruncate,
+			ColorMode:       p.ColorMode,
+			Field:           fieldFmt.Name,
 		}
 		formattedField := fieldFmt.format(&ctx, entry)
+		if formattedField != "" && p.Highlighter != nil {
+			formattedField = p.Highlighter.Transform(&ctx, formattedField)
+		}
 		if formattedField != "" {
+			sep := ""
 			if i != 0 && !strings.HasPrefix(formattedField, "\n") {
-				p.Out.Write([]byte(" "))
+				sep = " "
+			}
+			if fieldFmt.Wrap && !p.NoWrap && p.Width > 0 {
+				formattedField = p.wrap(formattedField, lastLineWidth(line.String()+sep))
+				if strings.HasPrefix(formattedField, "\n") {
+					// The field starts on the next line, so drop the padding left at the end of this one.
+					sep = ""
+					trimmed := strings.TrimRight(line.String(), " ")
+					line.Reset()
+					line.WriteString(trimmed)
+				}
 			}
-			p.Out.Write([]byte(formattedField))
+			line.WriteString(sep + formattedField)
+		}
+	}
+	out := line.String()
+	if p.NoWrap {
+		out = truncateWidth(strings.SplitN(out, "\n", 2)[0], p.Width)
+	}
+	io.WriteString(p.Out, out+"\n")
+}
+
+// wrap word wraps a field starting at column indent to the printer's width, with a hanging indent. If there is too
+// little room left after indent, continuation lines use the full width instead, and if not even the first word fits,
+// the field starts on the next line.
+func (p *CompactPrinter) wrap(field string, indent int) string {
+	if indent >= p.Width {
+		return field
+	}
+	if p.Width-indent < minWrapWidth {
+		if indent == 0 {
+			return strings.Join(wrapText(field, p.Width), "\n")
+		}
+		// Stand in for the text before the field with a word as wide as indent, including the space after it.
+		prefix := strings.Repeat("x", indent-1)
+		lines := wrapText(prefix+" "+field, p.Width)
+		if lines[0] == prefix {
+			return "\n" + strings.Join(lines[1:], "\n")
 		}
+		lines[0] = lines[0][indent:]
+		return strings.Join(lines, "\n")
 	}
-	p.Out.Write([]byte("\n"))
+	lines := wrapText(field, p.Width-indent)
+	return strings.Join(lines, "\n"+strings.Repeat(" ", indent))
 }
 
 func (f *FieldFmt) format(ctx *Context, entry *Entry) string {
@@ -106,6 +180,7 @@ func (f *FieldFmt) format(ctx *Context, entry *Entry) string {
 	if v == nil {
 		return ""
 	}
+	ctx.Value, ctx.Entry = v, entry
 
 	// Stringify the value
 	var s string
@@ -20,6 +20,17 @@ func TestCompactPrinter_PrintNonJSON(t *testing.T) {
 	assert.Equal(t, raw+"\n", buf.String())
 }
 
+func TestCompactPrinter_PrintSource(t *testing.T) {
+	buf := &bytes.Buffer{}
+	printer := NewCompactPrinter(buf)
+	printer.DisableColor = true
+	printer.Print(&Entry{Raw: []byte("hello world"), Source: "stderr"})
+	entry := &Entry{Raw: []byte(`{"level":"error","message":"boom"}`), Source: "stderr"}
+	require.NoError(t, json.Unmarshal(entry.Raw, &entry.Partials))
+	printer.Print(entry)
+	assert.Equal(t, "[stderr] hello world\n[stderr] ERRO boom\n", buf.String())
+}
+
 func TestCompactPrinter_Print(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -43,6 +54,10 @@ func TestCompactPrinter_Print(t *testing.T) {
 	github.com/pkg/errors_test.Example_stackTrace
 		/home/dfc/src/github.com/pkg/errors/example_test.go:127
 `,
+}, {
+		name:      "object_error",
+		json:      `{"level":"error","message":"repair failed","error":{"code":7, "parts":["axle"]}}`,
+		formatted: "ERRO repair failed {\"code\":7,\"parts\":[\"axle\"]}\n",
 	}}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -65,8 +80,8 @@ func TestCompactPrinter_PrintWithColors(t *testing.T) {
 		`{"timestamp":"2019-01-01 15:25:45","level":"info","thread":"repair-worker-2","logger":"truckrepairminion","message":"fixing truck 2, it's got a broken axle"}`,
 	}
 	var formatted = []string{
-		"\x1b[32mINFO\x1b[0m 2019-01-01 15:24:45 \x1b[32m[repair-worker-1] \x1b[0m \x1b[32m   truckrepairminion|\x1b[0m fixing truck 1, it's got a broken axle\n",
-		"\x1b[32mINFO\x1b[0m 2019-01-01 15:25:45 \x1b[33m[repair-worker-2] \x1b[0m \x1b[32m   truckrepairminion|\x1b[0m fixing truck 2, it's got a broken axle\n",
+		"\x1b[32mINFO\x1b[0m 2019-01-01 15:24:45 \x1b[33m[repair-worker-1] \x1b[0m \x1b[91m   truckrepairminion|\x1b[0m fixing truck 1, it's got a broken axle\n",
+		"\x1b[32mINFO\x1b[0m 2019-01-01 15:25:45 \x1b[90m[repair-worker-2] \x1b[0m \x1b[91m   truckrepairminion|\x1b[0m fixing truck 2, it's got a broken axle\n",
 	}
 	printer := NewCompactPrinter(nil)
 	for i, log := range logs {
@@ -80,3 +95,47 @@ func TestCompactPrinter_PrintWithColors(t *testing.T) {
 		assert.Equal(t, formatted[i], buf.String())
 	}
 }
+
+func TestCompactPrinter_PrintWrapped(t *testing.T) {
+	log := `{"timestamp":"2019-01-01 15:23:45","level":"INFO","thread":"truck-manager","message":"There are 7 more trucks in the garage to fix. Get to work."}`
+	tests := []struct {
+		name      string
+		width     int
+		noWrap    bool
+		formatted string
+	}{{
+		name:  "hanging indent",
+		width: 70,
+		formatted: "INFO 2019-01-01 15:23:45 [truck-manager]    There are 7 more trucks in\n" +
+			"                                            the garage to fix. Get to\n" +
+			"                                            work.\n",
+	}, {
+		name:      "narrow",
+		width:     60,
+		formatted: "INFO 2019-01-01 15:23:45 [truck-manager]    There are 7 more\ntrucks in the garage to fix. Get to work.\n",
+	}, {
+		name:      "first word does not fit",
+		width:     47,
+		formatted: "INFO 2019-01-01 15:23:45 [truck-manager]\nThere are 7 more trucks in the garage to fix.\nGet to work.\n",
+	}, {
+		name:      "no wrap",
+		width:     60,
+		noWrap:    true,
+		formatted: "INFO 2019-01-01 15:23:45 [truck-manager]    There are 7 mor…\n",
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			printer := NewCompactPrinter(buf)
+			printer.DisableColor = true
+			printer.Width = test.width
+			printer.NoWrap = test.noWrap
+			entry := &Entry{
+				Raw: []byte(log),
+			}
+			require.NoError(t, json.Unmarshal([]byte(log), &entry.Partials))
+			printer.Print(entry)
+			assert.Equal(t, test.formatted, buf.String())
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package jl
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// CRIDecoder decodes the logs written by container runtimes like containerd and CRI-O, which Kubernetes keeps under
+// /var/log/containers. Each line is a timestamp, the stream, a tag that is P for partial lines and F for full lines,
+// and the content:
+//
+//	2019-01-01T00:00:00.000000000Z stdout F {"level":"info","message":"hello"}
+//
+// Partial lines are joined with the line that completes them.
+type CRIDecoder struct {
+	// Content decodes the joined content of the lines. If it is nil, or does not accept the content, the entry is an
+	// object with the timestamp and the content as the message.
+	Content Decoder
+
+	partials *containerPartials
+}
+
+// NewCRIDecoder allocates and returns a new CRIDecoder that decodes the content of lines with content.
+func NewCRIDecoder(content Decoder) *CRIDecoder {
+	return &CRIDecoder{
+		Content:  content,
+		partials: newContainerPartials(),
+	}
+}
+
+func (d *CRIDecoder) Decode(line []byte) (*Entry, bool) {
+	fields := strings.SplitN(string(line), " ", 4)
+	if len(fields) < 3 || (fields[1] != "stdout" && fields[1] != "stderr") || (fields[2] != "P" && fields[2] != "F") {
+		return nil, false
+	}
+	if _, err := time.Parse(time.RFC3339Nano, fields[0]); err != nil {
+		return nil, false
+	}
+	var content string
+	if len(fields) == 4 {
+		content = fields[3]
+	}
+	timestamp, stream := fields[0], fields[1]
+	if fields[2] == "P" {
+		d.partials.add(timestamp, stream, content)
+		return nil, true
+	}
+	timestamp, content = d.partials.complete(timestamp, stream, content)
+	return containerEntry(d.Content, timestamp, stream, content), true
+}
+
+func (d *CRIDecoder) Drain() []*Entry {
+	return d.partials.drain(d.Content)
+}
+
+// DockerDecoder decodes the logs written by Docker's json-file logging driver, which are JSON objects with the line
+// the container wrote, the stream and a timestamp:
+//
+//	{"log":"hello\n","stream":"stdout","time":"2019-01-01T00:00:00.000000000Z"}
+//
+// Docker splits long lines into several objects, of which only the last ends with a newline. They are joined back
+// together.
+type DockerDecoder struct {
+	// Content decodes the joined content of the lines. If it is nil, or does not accept the content, the entry is an
+	// object with the timestamp and the content as the message.
+	Content Decoder
+
+	partials *containerPartials
+}
+
+// NewDockerDecoder allocates and returns a new DockerDecoder that decodes the content of lines with content.
+func NewDockerDecoder(content Decoder) *DockerDecoder {
+	return &DockerDecoder{
+		Content:  content,
+		partials: newContainerPartials(),
+	}
+}
+
+func (d *DockerDecoder) Decode(line []byte) (*Entry, bool) {
+	if !bytes.HasPrefix(bytes.TrimSpace(line), []byte("{")) {
+		return nil, false
+	}
+	var record struct {
+		Log    *string `json:"log"`
+		Stream string  `json:"stream"`
+		Time   string  `json:"time"`
+	}
+	if err := json.Unmarshal(line, &record); err != nil || record.Log == nil || record.Stream == "" {
+		return nil, false
+	}
+	content := *record.Log
+	if !strings.HasSuffix(content, "\n") {
+		d.partials.add(record.Time, record.Stream, content)
+		return nil, true
+	}
+	content = strings.TrimSuffix(strings.TrimSuffix(content, "\n"), "\r")
+	timestamp, content := d.partials.complete(record.Time, record.Stream, content)
+	return containerEntry(d.Content, timestamp, record.Stream, content), true
+}
+
+func (d *DockerDecoder) Drain() []*Entry {
+	return d.partials.drain(d.Content)
+}
+
+// containerPartials holds the partial lines of each stream of a container, until the line that completes them.
+type containerPartials struct {
+	timestamps map[string]string
+	contents   map[string]*strings.Builder
+}
+
+func newContainerPartials() *containerPartials {
+	return &containerPartials{
+		timestamps: make(map[string]string),
+		contents:   make(map[string]*strings.Builder),
+	}
+}
+
+func (p *containerPartials) add(timestamp, stream, content string) {
+	b, ok := p.contents[stream]
+	if !ok {
+		b = &strings.Builder{}
+		p.contents[stream] = b
+		p.timestamps[stream] = timestamp
+	}
+	b.WriteString(content)
+}
+
+// complete returns the timestamp of the first line held for the stream and the content of the lines joined with
+// content, and forgets them. If no lines are held, timestamp and content are returned as is.
+func (p *containerPartials) complete(timestamp, stream, content string) (string, string) {
+	b, ok := p.contents[stream]
+	if !ok {
+		return timestamp, content
+	}
+	b.WriteString(content)
+	timestamp = p.timestamps[stream]
+	delete(p.contents, stream)
+	delete(p.timestamps, stream)
+	return timestamp, b.String()
+}
+
+func (p *containerPartials) drain(content Decoder) []*Entry {
+	var entries []*Entry
+	for _, stream := range []string{"stdout", "stderr"} {
+		if _, ok := p.contents[stream]; ok {
+			timestamp, c := p.complete("", stream, "")
+			entries = append(entries, containerEntry(content, timestamp, stream, c))
+		}
+	}
+	return entries
+}
+
+// containerEntry decodes the content of a line written by a container. Entries from stderr have "stderr" as their
+// Source.
+func containerEntry(decoder Decoder, timestamp, stream, content string) *Entry {
+	var entry *Entry
+	if decoder != nil {
+		if e, ok := decoder.Decode([]byte(content)); ok && e != nil {
+			entry = e
+		}
+	}
+	if entry == nil {
+		obj := map[string]json.RawMessage{"message": marshalString(content)}
+		if timestamp != "" {
+			obj["timestamp"] = marshalString(timestamp)
+		}
+		entry = NewEntry(marshalRaw(obj))
+	}
+	if stream == "stderr" {
+		entry.Source = stream
+	}
+	return entry
+}
@@ -0,0 +1,37 @@
+package jl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCRIDecoder(t *testing.T) {
+	logs := `2019-01-01T00:00:00.1Z stdout F {"level":"info","message":"hello"}
+2019-01-01T00:00:00.2Z stderr P panic: 
+2019-01-01T00:00:00.3Z stdout F plain
+2019-01-01T00:00:00.4Z stderr F oops
+2019-01-01T00:00:00.5Z stdout F 
+2019-01-01T00:00:00.6Z stdout P never finished
+stdout F not cri`
+	assert.Equal(t, []string{
+		`{"level":"info","message":"hello"}`,
+		`{"message":"plain","timestamp":"2019-01-01T00:00:00.3Z"}`,
+		`[stderr] {"message":"panic: oops","timestamp":"2019-01-01T00:00:00.2Z"}`,
+		`{"message":"","timestamp":"2019-01-01T00:00:00.5Z"}`,
+		`stdout F not cri`,
+		`{"message":"never finished","timestamp":"2019-01-01T00:00:00.6Z"}`,
+	}, decodeLines(t, NewCRIDecoder(JSONDecoder), logs))
+}
+
+func TestDockerDecoder(t *testing.T) {
+	logs := `{"log":"{\"level\":\"info\",\"message\":\"hello\"}\n","stream":"stdout","time":"2019-01-01T00:00:00.1Z"}
+{"log":"a long ","stream":"stderr","time":"2019-01-01T00:00:00.2Z"}
+{"log":"line\r\n","stream":"stderr","time":"2019-01-01T00:00:00.3Z"}
+{"level":"info","message":"not docker"}`
+	assert.Equal(t, []string{
+		`{"level":"info","message":"hello"}`,
+		`[stderr] {"message":"a long line","timestamp":"2019-01-01T00:00:00.2Z"}`,
+		`{"level":"info","message":"not docker"}`,
+	}, decodeLines(t, NewDockerDecoder(JSONDecoder), logs))
+}
@@ -0,0 +1,106 @@
+package jl
+
+import (
+	"sort"
+)
+
+// Decoder decodes lines of input into entries.
+type Decoder interface {
+	// Decode decodes a line into an entry. It returns false if the line is not in the decoder's format. Decoders that
+	// join several lines into one entry return a nil entry and true for the lines they hold on to.
+	Decode(line []byte) (*Entry, bool)
+}
+
+// DecoderFunc adapts a function to a Decoder.
+type DecoderFunc func(line []byte) (*Entry, bool)
+
+func (f DecoderFunc) Decode(line []byte) (*Entry, bool) {
+	return f(line)
+}
+
+// Drainer is implemented by Decoders that hold on to lines before decoding them.
+type Drainer interface {
+	// Drain returns the entries decoded from the lines held by the decoder, and forgets them.
+	Drain() []*Entry
+}
+
+// Decoders are the decoders that can be selected by name, like with jl's -input flag. Each function returns a new
+// decoder, since decoders may hold state for the input they decode. Other packages may add their own.
+var Decoders = map[string]func() Decoder{
+	"json":   func() Decoder { return JSONDecoder },
+	"logfmt": func() Decoder { return MultiDecoder(JSONDecoder, LogfmtDecoder) },
+	"cri":    func() Decoder { return NewCRIDecoder(JSONDecoder) },
+	"docker": func() Decoder { return NewDockerDecoder(JSONDecoder) },
+	"syslog": func() Decoder { return SyslogDecoder },
+	"clef":   func() Decoder { return CLEFDecoder },
+	// A RegexDecoder without patterns, which decodes nothing until its Patterns are set.
+	"regex": func() Decoder { return &RegexDecoder{} },
+	// Plain-text formats, see TextFormats.
+	"nginx":    func() Decoder { return textFormatDecoder("nginx") },
+	"apache":   func() Decoder { return textFormatDecoder("apache") },
+	"postgres": func() Decoder { return textFormatDecoder("postgres") },
+	"log4j":    func() Decoder { return textFormatDecoder("log4j") },
+	"auto":     func() Decoder { return NewAutoDecoder() },
+}
+
+// DecoderNames returns the sorted names of the registered decoders.
+func DecoderNames() []string {
+	names := make([]string, 0, len(Decoders))
+	for name := range Decoders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// JSONDecoder decodes lines that are JSON objects. It is the decoder used by Parser by default.
+var JSONDecoder Decoder = DecoderFunc(func(line []byte) (*Entry, bool) {
+	entry := NewEntry(line)
+	return entry, entry.Partials != nil
+})
+
+// MultiDecoder returns a decoder that decodes each line with the first of the decoders that accepts it.
+func MultiDecoder(decoders ...Decoder) Decoder {
+	return multiDecoder(decoders)
+}
+
+type multiDecoder []Decoder
+
+func (m multiDecoder) Decode(line []byte) (*Entry, bool) {
+	for _, d := range m {
+		if entry, ok := d.Decode(line); ok {
+			return entry, true
+		}
+	}
+	return nil, false
+}
+
+func (m multiDecoder) Drain() []*Entry {
+	var entries []*Entry
+	for _, d := range m {
+		entries = append(entries, drain(d)...)
+	}
+	return entries
+}
+
+// drain drains the decoder if it is a Drainer.
+func drain(d Decoder) []*Entry {
+	if drainer, ok := d.(Drainer); ok {
+		return drainer.Drain()
+	}
+	return nil
+}
+
+// NewAutoDecoder returns a decoder that detects the format of each line. It decodes Docker and CRI container logs,
+// syslog messages, CLEF events, JSON objects and logfmt.
+func NewAutoDecoder() Decoder {
+	content := MultiDecoder(CLEFDecoder, JSONDecoder, LogfmtDecoder)
+	return MultiDecoder(
+		NewDockerDecoder(content),
+		CLEFDecoder,
+		JSONDecoder,
+		NewCRIDecoder(content),
+		SyslogDecoder,
+		LogfmtDecoder,
+	)
+}
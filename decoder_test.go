@@ -0,0 +1,76 @@
+package jl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sourcePrinter records the raw lines of the entries it prints, prefixed with their source.
+type sourcePrinter struct {
+	lines []string
+}
+
+func (p *sourcePrinter) Print(entry *Entry) {
+	p.lines = append(p.lines, sourceTag(entry, true, ColorModeNone)+string(entry.Raw))
+}
+
+// decodeLines decodes the lines of logs with a parser using the decoder, and returns the raw lines of the entries.
+func decodeLines(t *testing.T, decoder Decoder, logs string) []string {
+	printer := &sourcePrinter{}
+	parser := NewParser(strings.NewReader(logs), printer)
+	parser.Decoder = decoder
+	require.NoError(t, parser.Consume())
+	return printer.lines
+}
+
+func TestParser_DefaultDecoder(t *testing.T) {
+	printer := &sourcePrinter{}
+	parser := NewParser(strings.NewReader("{\"msg\":\"hi\"}\nnot json\n[1]"), printer)
+	parser.Source = "app"
+	require.NoError(t, parser.Consume())
+	assert.Equal(t, []string{`[app] {"msg":"hi"}`, "[app] not json", "[app] [1]"}, printer.lines)
+}
+
+func TestMultiDecoder(t *testing.T) {
+	upper := DecoderFunc(func(line []byte) (*Entry, bool) {
+		if strings.ToUpper(string(line)) != string(line) {
+			return nil, false
+		}
+		return NewEntry(marshalRaw(map[string]string{"message": string(line)})), true
+	})
+	lines := decodeLines(t, MultiDecoder(JSONDecoder, upper), "{\"a\":1}\nHELLO\nhello")
+	assert.Equal(t, []string{`{"a":1}`, `{"message":"HELLO"}`, "hello"}, lines)
+}
+
+func TestAutoDecoder(t *testing.T) {
+	logs := strings.Join([]string{
+		`{"level":"info","message":"json"}`,
+		`{"log":"{\"message\":\"docker\"}\n","stream":"stderr","time":"2019-01-01T00:00:00Z"}`,
+		`2019-01-01T00:00:00Z stdout F cri`,
+		`<13>app[7]: syslog`,
+		`{"@t":"2019-01-01T00:00:00Z","@m":"clef"}`,
+		`level=warn msg=logfmt`,
+		`plain text`,
+	}, "\n")
+	assert.Equal(t, []string{
+		`{"level":"info","message":"json"}`,
+		`[stderr] {"message":"docker"}`,
+		`{"message":"cri","timestamp":"2019-01-01T00:00:00Z"}`,
+		`{"level":"notice","app":"app","pid":"7","message":"syslog"}`,
+		`{"@t":"2019-01-01T00:00:00Z","@m":"clef"}`,
+		`level=warn msg=logfmt`,
+		`plain text`,
+	}, decodeLines(t, NewAutoDecoder(), logs))
+}
+
+func TestDecoders(t *testing.T) {
+	for _, name := range DecoderNames() {
+		d := Decoders[name]()
+		assert.NotNil(t, d, name)
+		_, ok := d.Decode([]byte("plain text"))
+		assert.False(t, ok, name)
+	}
+}
@@ -0,0 +1,121 @@
+package jl
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultDedupeFields are the fields compared by DedupePrinter: the level, logger and message.
+var DefaultDedupeFields = []FieldFinder{LevelFinder, LoggerFinder, MessageFinder}
+
+// DedupePrinter collapses runs of consecutive entries whose selected fields are equal. The first entry of a run is held
+// back until an entry with different fields arrives or the printer is flushed, and is then printed with the number of
+// entries in the run and the time between the first and last of them.
+type DedupePrinter struct {
+	// Out is the writer where entries are written to.
+	Out io.Writer
+	// Printer formats entries. It must write to Buffer.
+	Printer EntryPrinter
+	// Buffer is the writer that Printer writes to.
+	Buffer *bytes.Buffer
+	// Fields locate the fields that must be equal for entries to be duplicates. Lines that are not JSON, or that have
+	// none of the fields, are compared in full.
+	Fields []FieldFinder
+	// DisableColor disables ANSI escape sequences in the repeat counter.
+	DisableColor bool
+
+	mu          sync.Mutex
+	first       *Entry
+	key         string
+	count       int
+	start, stop time.Time
+}
+
+// NewDedupePrinter allocates and returns a new DedupePrinter comparing DefaultDedupeFields. newPrinter is called once
+// to create the printer used to format entries.
+func NewDedupePrinter(w io.Writer, newPrinter func(w io.Writer) EntryPrinter) *DedupePrinter {
+	buf := &bytes.Buffer{}
+	return &DedupePrinter{
+		Out:     w,
+		Printer: newPrinter(buf),
+		Buffer:  buf,
+		Fields:  DefaultDedupeFields,
+	}
+}
+
+func (p *DedupePrinter) Print(entry *Entry) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	key := p.dedupeKey(entry)
+	ts, hasTime := EntryTime(entry)
+	if p.first != nil && key == p.key {
+		p.count++
+		if hasTime {
+			if p.start.IsZero() || ts.Before(p.start) {
+				p.start = ts
+			}
+			if ts.After(p.stop) {
+				p.stop = ts
+			}
+		}
+		return
+	}
+	p.printRun()
+	p.first, p.key, p.count = entry, key, 1
+	p.start, p.stop = time.Time{}, time.Time{}
+	if hasTime {
+		p.start, p.stop = ts, ts
+	}
+}
+
+// Flush prints the current run of entries.
+func (p *DedupePrinter) Flush() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.printRun()
+	p.first = nil
+	Flush(p.Printer)
+}
+
+// dedupeKey returns the values of the fields compared by the printer, joined into a string.
+func (p *DedupePrinter) dedupeKey(entry *Entry) string {
+	if entry.Partials == nil {
+		return string(entry.Raw)
+	}
+	values := make([]string, len(p.Fields))
+	found := false
+	for i, finder := range p.Fields {
+		values[i] = findString(finder, entry)
+		found = found || values[i] != ""
+	}
+	if !found {
+		return string(entry.Raw)
+	}
+	return strings.Join(values, "\x00")
+}
+
+// printRun prints the first entry of the current run, followed by the repeat counter if it has more than one entry.
+func (p *DedupePrinter) printRun() {
+	if p.first == nil {
+		return
+	}
+	p.Buffer.Reset()
+	p.Printer.Print(p.first)
+	if p.count == 1 {
+		p.Out.Write(p.Buffer.Bytes())
+		return
+	}
+	counter := fmt.Sprintf("×%d", p.count)
+	if d := p.stop.Sub(p.start); d > 0 {
+		counter += " over " + d.String()
+	}
+	if !p.DisableColor {
+		counter = Style{Dim: true}.Render(ColorModeTrueColor, counter)
+	}
+	formatted := strings.TrimSuffix(p.Buffer.String(), "\n")
+	io.WriteString(p.Out, formatted+" "+counter+"\n")
+}
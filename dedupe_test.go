@@ -0,0 +1,35 @@
+package jl
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDedupePrinter_Print(t *testing.T) {
+	logs := `{"timestamp":"2019-01-01T15:24:45Z","level":"info","message":"retrying","attempt":1}
+{"timestamp":"2019-01-01T15:24:50Z","level":"info","message":"retrying","attempt":2}
+{"timestamp":"2019-01-01T15:25:45Z","level":"info","message":"retrying","attempt":3}
+{"timestamp":"2019-01-01T15:26:45Z","level":"error","message":"retrying","attempt":4}
+not json
+not json
+{"timestamp":"2019-01-01T15:27:45Z","level":"info","message":"done"}`
+	buf := &bytes.Buffer{}
+	printer := NewDedupePrinter(buf, func(w io.Writer) EntryPrinter {
+		lp := NewLogfmtPrinter(w)
+		lp.DisableColor = true
+		return lp
+	})
+	printer.DisableColor = true
+	for _, entry := range parseLines(t, logs) {
+		printer.Print(entry)
+	}
+	printer.Flush()
+	assert.Equal(t, `timestamp=2019-01-01T15:24:45Z level=info message=retrying attempt=1 ×3 over 1m0s
+timestamp=2019-01-01T15:26:45Z level=error message=retrying attempt=4
+not json ×2
+timestamp=2019-01-01T15:27:45Z level=info message=done
+`, buf.String())
+}
@@ -0,0 +1,217 @@
+package jl
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// maxEmbeddedDepth is the maximum depth of JSON embedded in strings that EmbeddedJSONDecoder decodes.
+const maxEmbeddedDepth = 10
+
+// EmbeddedJSONDecoder wraps a decoder, and decodes the JSON objects and arrays that are embedded in string fields of
+// the entries it decodes, like {"message":"{\"event\":\"order\"}"}. The decoded values replace the strings in the
+// entry's Partials, so that finders can reach inside them, like ByNames("message.event"), and printers print them as
+// nested structures. Raw is left as is. JSON embedded in the decoded values is decoded too.
+type EmbeddedJSONDecoder struct {
+	// Decoder decodes the lines into entries.
+	Decoder Decoder
+	// Fields are the paths of the fields to decode, like ByNames accepts. If empty, all string fields holding JSON
+	// objects or arrays are decoded, at any depth.
+	Fields []string
+}
+
+// NewEmbeddedJSONDecoder allocates and returns a new EmbeddedJSONDecoder that decodes the JSON embedded in the fields
+// of the entries decoded by decoder, or in all of their fields if none are given.
+func NewEmbeddedJSONDecoder(decoder Decoder, fields ...string) *EmbeddedJSONDecoder {
+	return &EmbeddedJSONDecoder{
+		Decoder: decoder,
+		Fields:  fields,
+	}
+}
+
+func (d *EmbeddedJSONDecoder) Decode(line []byte) (*Entry, bool) {
+	entry, ok := d.Decoder.Decode(line)
+	if ok && entry != nil {
+		d.decodeEmbedded(entry)
+	}
+	return entry, ok
+}
+
+func (d *EmbeddedJSONDecoder) Drain() []*Entry {
+	entries := drain(d.Decoder)
+	for _, entry := range entries {
+		d.decodeEmbedded(entry)
+	}
+	return entries
+}
+
+func (d *EmbeddedJSONDecoder) decodeEmbedded(entry *Entry) {
+	if entry.Partials == nil {
+		return
+	}
+	if len(d.Fields) == 0 {
+		for k, v := range entry.Partials {
+			if decoded, ok := decodeEmbeddedJSON(v, true, 0); ok {
+				entry.Partials[k] = decoded
+			}
+		}
+		return
+	}
+	for _, name := range d.Fields {
+		path, err := parsePath(name)
+		if err != nil {
+			path = fieldPath{{kind: pathKey, key: name}}
+		}
+		decodeEmbeddedPath(entry.Partials, path)
+	}
+}
+
+// decodeEmbeddedPath decodes the JSON embedded in the fields at the path in obj, and returns whether it did. Values on
+// the path that are strings holding JSON are decoded to reach into them.
+func decodeEmbeddedPath(obj map[string]json.RawMessage, path fieldPath) bool {
+	seg := path[0]
+	switch seg.kind {
+	case pathWildcard:
+		changed := false
+		for k, v := range obj {
+			if decoded, ok := decodeEmbeddedValue(v, path[1:]); ok {
+				obj[k] = decoded
+				changed = true
+			}
+		}
+		return changed
+	case pathKey:
+		// Like ByNames, keys are looked up in nested objects first, and then as keys containing dots.
+		key := seg.key
+		for n := 1; n <= len(path); n++ {
+			if n > 1 {
+				if path[n-1].kind != pathKey || !path[n-1].dotted {
+					return false
+				}
+				key += "." + path[n-1].key
+			}
+			if v, ok := obj[key]; ok {
+				if decoded, ok := decodeEmbeddedValue(v, path[n:]); ok {
+					obj[key] = decoded
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// decodeEmbeddedValue decodes the JSON embedded in the values at the path in v, and returns v with them decoded.
+func decodeEmbeddedValue(v json.RawMessage, path fieldPath) (json.RawMessage, bool) {
+	if len(path) == 0 {
+		return decodeEmbeddedJSON(v, false, 0)
+	}
+	if decoded, ok := decodeEmbeddedString(v); ok {
+		v = decoded
+	}
+	switch v = bytes.TrimSpace(v); {
+	case len(v) > 0 && v[0] == '{':
+		var obj map[string]json.RawMessage
+		if json.Unmarshal(v, &obj) != nil || !decodeEmbeddedPath(obj, path) {
+			return nil, false
+		}
+		return marshalRaw(obj), true
+	case len(v) > 0 && v[0] == '[' && path[0].kind != pathKey:
+		var arr []json.RawMessage
+		if json.Unmarshal(v, &arr) != nil {
+			return nil, false
+		}
+		changed := false
+		for i := range arr {
+			if path[0].kind == pathIndex && i != path[0].index && i != path[0].index+len(arr) {
+				continue
+			}
+			if decoded, ok := decodeEmbeddedValue(arr[i], path[1:]); ok {
+				arr[i] = decoded
+				changed = true
+			}
+		}
+		if !changed {
+			return nil, false
+		}
+		return marshalRaw(arr), true
+	}
+	return nil, false
+}
+
+// decodeEmbeddedJSON decodes v if it is a string holding a JSON object or array, and the JSON embedded in the decoded
+// value. If deep is set, the JSON embedded in the values of objects and arrays is decoded too. It returns false if
+// nothing was decoded.
+func decodeEmbeddedJSON(v json.RawMessage, deep bool, depth int) (json.RawMessage, bool) {
+	if depth > maxEmbeddedDepth {
+		return nil, false
+	}
+	v = bytes.TrimSpace(v)
+	if len(v) == 0 {
+		return nil, false
+	}
+	switch v[0] {
+	case '"':
+		decoded, ok := decodeEmbeddedString(v)
+		if !ok {
+			return nil, false
+		}
+		if nested, ok := decodeEmbeddedJSON(decoded, true, depth+1); ok {
+			return nested, true
+		}
+		return decoded, true
+	case '{':
+		if !deep {
+			return nil, false
+		}
+		var obj map[string]json.RawMessage
+		if json.Unmarshal(v, &obj) != nil {
+			return nil, false
+		}
+		changed := false
+		for k, value := range obj {
+			if decoded, ok := decodeEmbeddedJSON(value, true, depth+1); ok {
+				obj[k] = decoded
+				changed = true
+			}
+		}
+		if !changed {
+			return nil, false
+		}
+		return marshalRaw(obj), true
+	case '[':
+		if !deep {
+			return nil, false
+		}
+		var arr []json.RawMessage
+		if json.Unmarshal(v, &arr) != nil {
+			return nil, false
+		}
+		changed := false
+		for i, value := range arr {
+			if decoded, ok := decodeEmbeddedJSON(value, true, depth+1); ok {
+				arr[i] = decoded
+				changed = true
+			}
+		}
+		if !changed {
+			return nil, false
+		}
+		return marshalRaw(arr), true
+	}
+	return nil, false
+}
+
+// decodeEmbeddedString returns the JSON object or array held by the string v.
+func decodeEmbeddedString(v json.RawMessage) (json.RawMessage, bool) {
+	var s string
+	if json.Unmarshal(v, &s) != nil {
+		return nil, false
+	}
+	embedded := []byte(strings.TrimSpace(s))
+	if len(embedded) == 0 || embedded[0] != '{' && embedded[0] != '[' || !json.Valid(embedded) {
+		return nil, false
+	}
+	return embedded, true
+}
@@ -0,0 +1,88 @@
+package jl
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddedJSONDecoder(t *testing.T) {
+	line := `{"message":"{\"event\":\"order\",\"payload\":\"{\\\"id\\\":42}\"}","data":{"body":"[1, 2]"},"text":"{not json","n":1}`
+	tests := []struct {
+		name     string
+		fields   []string
+		partials map[string]string
+	}{{
+		name: "auto",
+		partials: map[string]string{
+			"message": `{"event":"order","payload":{"id":42}}`,
+			"data":    `{"body":[1,2]}`,
+			"text":    `"{not json"`,
+			"n":       `1`,
+		},
+	}, {
+		name:   "fields",
+		fields: []string{"data.body", "text", "missing.field"},
+		partials: map[string]string{
+			"message": `"{\"event\":\"order\",\"payload\":\"{\\\"id\\\":42}\"}"`,
+			"data":    `{"body":[1,2]}`,
+			"text":    `"{not json"`,
+			"n":       `1`,
+		},
+	}, {
+		name:   "paths",
+		fields: []string{"message.payload", "data[0]"},
+		partials: map[string]string{
+			"message": `{"event":"order","payload":{"id":42}}`,
+			"data":    `{"body":"[1, 2]"}`,
+			"text":    `"{not json"`,
+			"n":       `1`,
+		},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			entry, ok := NewEmbeddedJSONDecoder(JSONDecoder, test.fields...).Decode([]byte(line))
+			require.True(t, ok)
+			partials := make(map[string]string)
+			for k, v := range entry.Partials {
+				partials[k] = string(v)
+			}
+			assert.Equal(t, test.partials, partials)
+			assert.Equal(t, line, string(entry.Raw))
+		})
+	}
+}
+
+func TestEmbeddedJSONDecoder_Finders(t *testing.T) {
+	entry, ok := NewEmbeddedJSONDecoder(JSONDecoder, "message").Decode([]byte(`{"message":"{\"event\":\"order\"}"}`))
+	require.True(t, ok)
+	assert.Equal(t, json.RawMessage(`"order"`), ByNames("message.event")(entry))
+
+	buf := &bytes.Buffer{}
+	printer := NewCompactPrinter(buf)
+	printer.DisableColor = true
+	printer.Print(entry)
+	assert.Equal(t, " {\"event\":\"order\"}\n", buf.String())
+}
+
+func TestEmbeddedJSONDecoder_ArrayPaths(t *testing.T) {
+	line := `{"events":[{"body":"{\"a\":1}"},{"body":"{\"b\":2}"}]}`
+	tests := []struct {
+		path   string
+		events string
+	}{
+		{"events[*].body", `[{"body":{"a":1}},{"body":{"b":2}}]`},
+		{"events[-1].body", `[{"body":"{\"a\":1}"},{"body":{"b":2}}]`},
+		{"events.*.body", `[{"body":{"a":1}},{"body":{"b":2}}]`},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			entry, ok := NewEmbeddedJSONDecoder(JSONDecoder, test.path).Decode([]byte(line))
+			require.True(t, ok)
+			assert.Equal(t, test.events, string(entry.Partials["events"]))
+		})
+	}
+}
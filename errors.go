@@ -5,3 +5,10 @@ type LogrusError struct {
 	Error string
 	Stack string
 }
+
+// Continuation holds the continuation lines that the Parser attached to an entry, like a stack trace, along with the
+// error logged in the entry, if any.
+type Continuation struct {
+	Error interface{}
+	Lines string
+}
@@ -0,0 +1,253 @@
+package jl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultMaxCardinality is the default FieldScanner.MaxCardinality.
+const DefaultMaxCardinality = 1000
+
+// FieldStats describes the values a field took in the scanned entries.
+type FieldStats struct {
+	// Path is the dotted path of the field, as accepted by ByNames. Keys with dots, brackets, backslashes or wildcards
+	// in them are escaped, as in d.e\.f for the key "e.f" of the object "d".
+	Path string
+	// Count is the number of entries that have the field.
+	Count int
+	// Types counts the values of the field by their JSON type: "string", "number", "boolean", "null", "object" or
+	// "array".
+	Types map[string]int
+	// Capped is set if the field had more than MaxCardinality distinct values, and values stopped being counted.
+	Capped bool
+
+	values map[string]int
+}
+
+// Cardinality returns the number of distinct scalar values of the field. If Capped is set, it is a lower bound.
+func (f *FieldStats) Cardinality() int {
+	return len(f.values)
+}
+
+// TopValues returns up to n of the most common scalar values of the field, with their counts. Ties are broken
+// alphabetically.
+func (f *FieldStats) TopValues(n int) []ValueCount {
+	top := make([]ValueCount, 0, len(f.values))
+	for v, count := range f.values {
+		top = append(top, ValueCount{Value: v, Count: count})
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Count != top[j].Count {
+			return top[i].Count > top[j].Count
+		}
+		return top[i].Value < top[j].Value
+	})
+	if len(top) > n {
+		top = top[:n]
+	}
+	return top
+}
+
+// ValueCount is a value of a field and the number of entries it appeared in.
+type ValueCount struct {
+	// Value is formatted as JSON, except that strings are quoted with Go escapes.
+	Value string
+	Count int
+}
+
+// FieldScanner discovers the fields of JSON log entries, including nested ones, and collects statistics about their
+// values.
+type FieldScanner struct {
+	// MaxCardinality is the number of distinct values counted per field, to bound memory use on fields like IDs.
+	MaxCardinality int
+
+	mu      sync.Mutex
+	entries int
+	fields  map[string]*FieldStats
+}
+
+// NewFieldScanner allocates and returns a new FieldScanner.
+func NewFieldScanner() *FieldScanner {
+	return &FieldScanner{
+		MaxCardinality: DefaultMaxCardinality,
+		fields:         make(map[string]*FieldStats),
+	}
+}
+
+// Add records the fields of the entry. Lines that are not JSON are counted as entries without fields.
+func (s *FieldScanner) Add(entry *Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries++
+	if entry.Partials != nil {
+		s.addObject("", entry.Partials)
+	}
+}
+
+// Entries returns the number of entries scanned.
+func (s *FieldScanner) Entries() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.entries
+}
+
+// Fields returns the statistics of every field seen, ordered by path.
+func (s *FieldScanner) Fields() []*FieldStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fields := make([]*FieldStats, 0, len(s.fields))
+	for _, f := range s.fields {
+		fields = append(fields, f)
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Path < fields[j].Path
+	})
+	return fields
+}
+
+func (s *FieldScanner) addObject(path string, obj map[string]json.RawMessage) {
+	for k, v := range obj {
+		s.addValue(joinPath(path, k), v)
+	}
+}
+
+func (s *FieldScanner) addValue(path string, v json.RawMessage) {
+	f, ok := s.fields[path]
+	if !ok {
+		f = &FieldStats{
+			Path:   path,
+			Types:  make(map[string]int),
+			values: make(map[string]int),
+		}
+		s.fields[path] = f
+	}
+	f.Count++
+	typ := jsonType(v)
+	f.Types[typ]++
+	switch typ {
+	case "object":
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(v, &obj); err == nil {
+			s.addObject(path, obj)
+		}
+		return
+	case "array":
+		return
+	}
+	value := strings.TrimSpace(string(v))
+	if typ == "string" {
+		value = strconv.Quote(toString(v))
+	}
+	if _, ok := f.values[value]; ok || len(f.values) < s.MaxCardinality {
+		f.values[value]++
+	} else {
+		f.Capped = true
+	}
+}
+
+// jsonType returns the JSON type of the value.
+func jsonType(v json.RawMessage) string {
+	trimmed := strings.TrimSpace(string(v))
+	if trimmed == "" {
+		return "null"
+	}
+	switch trimmed[0] {
+	case '{':
+		return "object"
+	case '[':
+		return "array"
+	case '"':
+		return "string"
+	case 't', 'f':
+		return "boolean"
+	case 'n':
+		return "null"
+	}
+	return "number"
+}
+
+// FieldsPrinter scans entries with a FieldScanner, and prints a table of the fields found when flushed.
+type FieldsPrinter struct {
+	// Out is the writer where the report is written to.
+	Out io.Writer
+	// Scanner collects the fields of the entries.
+	Scanner *FieldScanner
+	// Top is the number of most common values printed for each field.
+	Top int
+	// DisableColor disables ANSI escape sequences.
+	DisableColor bool
+
+	mu sync.Mutex
+}
+
+// NewFieldsPrinter allocates and returns a new FieldsPrinter.
+func NewFieldsPrinter(w io.Writer) *FieldsPrinter {
+	return &FieldsPrinter{
+		Out:     w,
+		Scanner: NewFieldScanner(),
+		Top:     3,
+	}
+}
+
+func (p *FieldsPrinter) Print(entry *Entry) {
+	p.Scanner.Add(entry)
+}
+
+// maxValueWidth is the number of columns that values in the report are truncated to.
+const maxValueWidth = 30
+
+// Flush prints the report.
+func (p *FieldsPrinter) Flush() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fields := p.Scanner.Fields()
+	if len(fields) == 0 {
+		return
+	}
+	rows := [][]string{{"FIELD", "TYPES", "PRESENT", "DISTINCT", "TOP VALUES"}}
+	for _, f := range fields {
+		var types []string
+		for typ := range f.Types {
+			types = append(types, typ)
+		}
+		sort.Strings(types)
+		present := fmt.Sprintf("%.1f%%", 100*float64(f.Count)/float64(p.Scanner.Entries()))
+		distinct := strconv.Itoa(f.Cardinality())
+		if f.Capped {
+			distinct = ">" + distinct
+		}
+		var top []string
+		for _, vc := range f.TopValues(p.Top) {
+			top = append(top, fmt.Sprintf("%s (%d)", truncateWidth(vc.Value, maxValueWidth), vc.Count))
+		}
+		rows = append(rows, []string{f.Path, strings.Join(types, "|"), present, distinct, strings.Join(top, ", ")})
+	}
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			if w := DisplayWidth(cell); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+	for i, row := range rows {
+		var line strings.Builder
+		for j, cell := range row {
+			if j == len(row)-1 {
+				line.WriteString(cell)
+				break
+			}
+			line.WriteString(cell + strings.Repeat(" ", widths[j]-DisplayWidth(cell)+2))
+		}
+		text := strings.TrimRight(line.String(), " ")
+		if i == 0 && !p.DisableColor {
+			text = Style{Bold: true}.Render(ColorModeTrueColor, text)
+		}
+		fmt.Fprintln(p.Out, text)
+	}
+}
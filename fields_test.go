@@ -0,0 +1,77 @@
+package jl
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldScanner_Add(t *testing.T) {
+	logs := `{"level":"info","http":{"status":200,"path":"/"},"tags":["a"]}
+{"level":"info","http":{"status":500,"path":"/"}}
+{"level":"error","http":null,"retry":true}
+not json`
+	scanner := NewFieldScanner()
+	scanner.MaxCardinality = 1
+	for _, entry := range parseLines(t, logs) {
+		scanner.Add(entry)
+	}
+	assert.Equal(t, 4, scanner.Entries())
+	fields := scanner.Fields()
+	var paths []string
+	for _, f := range fields {
+		paths = append(paths, f.Path)
+	}
+	require.Equal(t, []string{"http", "http.path", "http.status", "level", "retry", "tags"}, paths)
+
+	http := fields[0]
+	assert.Equal(t, 3, http.Count)
+	assert.Equal(t, map[string]int{"object": 2, "null": 1}, http.Types)
+
+	status := fields[2]
+	assert.Equal(t, map[string]int{"number": 2}, status.Types)
+	assert.True(t, status.Capped)
+	assert.False(t, fields[4].Capped)
+	assert.Equal(t, []ValueCount{{"200", 1}}, status.TopValues(3))
+
+	level := fields[3]
+	assert.True(t, level.Capped)
+	assert.Equal(t, 1, level.Cardinality())
+	assert.Equal(t, []ValueCount{{`"info"`, 2}}, level.TopValues(3))
+}
+
+func TestFieldsPrinter_Flush(t *testing.T) {
+	logs := `{"level":"info","msg":"started"}
+{"level":"info","msg":"stopped","code":1}`
+	buf := &bytes.Buffer{}
+	printer := NewFieldsPrinter(buf)
+	printer.DisableColor = true
+	for _, entry := range parseLines(t, logs) {
+		printer.Print(entry)
+	}
+	printer.Flush()
+	assert.Equal(t, `FIELD  TYPES   PRESENT  DISTINCT  TOP VALUES
+code   number  50.0%    1         1 (1)
+level  string  100.0%   1         "info" (2)
+msg    string  100.0%   2         "started" (1), "stopped" (1)
+`, buf.String())
+}
+
+func TestFieldScanner_PathsFoundByNames(t *testing.T) {
+	logs := `{"a[0]":1,"b\\c":{"*":2,"":3},"http.status":4,"d":{"e.f":5}}`
+	scanner := NewFieldScanner()
+	entry := parseLines(t, logs)[0]
+	scanner.Add(entry)
+	var paths []string
+	for _, f := range scanner.Fields() {
+		paths = append(paths, f.Path)
+		if f.Types["object"] == 0 {
+			_, err := ByPath(f.Path)
+			require.NoError(t, err)
+			assert.NotNil(t, ByNames(f.Path)(entry), f.Path)
+		}
+	}
+	assert.Equal(t, []string{`a\[0]`, `b\\c`, `b\\c[""]`, `b\\c["*"]`, "d", `d.e\.f`, `http\.status`}, paths)
+}
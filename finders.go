@@ -2,17 +2,33 @@ package jl
 
 import (
 	"encoding/json"
-	"strings"
 )
 
 // FieldFinder locates a field in the Entry and returns it.
 type FieldFinder func(entry *Entry) interface{}
 
-// ByNames locates fields by their top-level key name in the JSON log entry, and returns the field as a json.RawMessage.
+// ByNames locates fields by their names, and returns the first one found as a json.RawMessage. Names are paths of keys
+// separated by dots, which descend into nested objects, like "http.request.method". Keys may also be written as
+// ["key"] or ['key'], and dots in keys escaped as \., to reach keys with dots or brackets in them. Since keys with
+// dots are common, like "http.status" in ECS logs, a dotted path that does not lead into nested objects also finds
+// keys named by the dotted path, or part of it.
+//
+// Elements of arrays are selected by index, counting from the end if negative, and * or [*] selects all the values
+// of an object or array, as in "errors[0].message", "errors[-1]", "tags.*" or "spans[*].name". A path with a
+// wildcard finds a JSON array of all the values it matches. Names that are not valid paths are looked up as
+// top-level keys.
 func ByNames(names ...string) FieldFinder {
+	paths := make([]fieldPath, len(names))
+	for i, name := range names {
+		path, err := parsePath(name)
+		if err != nil {
+			path = fieldPath{{kind: pathKey, key: name}}
+		}
+		paths[i] = path
+	}
 	return func(entry *Entry) interface{} {
-		for _, name := range names {
-			if v, ok := getDeep(entry, name); ok {
+		for _, path := range paths {
+			if v, ok := path.find(entry.Partials); ok {
 				return v
 			}
 		}
@@ -20,25 +36,40 @@ func ByNames(names ...string) FieldFinder {
 	}
 }
 
-func getDeep(entry *Entry, name string) (interface{}, bool) {
-	parts := strings.SplitN(name, ".", 2)
-	key := parts[0]
-	v, ok := entry.Partials[key]
-	if !ok {
-		return nil, false
-	}
-	if len(parts) == 1 {
-		return v, true
+// ByPath locates a field by its path, like ByNames, but returns an error if the path is not valid.
+func ByPath(path string) (FieldFinder, error) {
+	if _, err := parsePath(path); err != nil {
+		return nil, err
 	}
-	var partials map[string]json.RawMessage
-	err := json.Unmarshal(v, &partials)
-	if err != nil {
-		return nil, false
+	return ByNames(path), nil
+}
+
+// MessageFinder finds the message of an entry, using the same field names as DefaultCompactPrinterFieldFmt.
+var MessageFinder = ByNames("message", "msg", "textPayload", "jsonPayload.message")
+
+// LoggerFinder finds the logger of an entry, using the same field names as DefaultCompactPrinterFieldFmt.
+var LoggerFinder = ByNames("logger", "caller")
+
+// ContinuationFinder returns a FieldFinder that finds the continuation lines attached to an entry by the Parser, and
+// returns them as a Continuation, with the error found by the first of errorFinders that finds one.
+func ContinuationFinder(errorFinders ...FieldFinder) FieldFinder {
+	lines := ByNames(ContinuationField)
+	return func(entry *Entry) interface{} {
+		v, ok := lines(entry).(json.RawMessage)
+		if !ok {
+			return nil
+		}
+		var c Continuation
+		if err := json.Unmarshal(v, &c.Lines); err != nil {
+			return nil
+		}
+		for _, finder := range errorFinders {
+			if c.Error = finder(entry); c.Error != nil {
+				break
+			}
+		}
+		return c
 	}
-	return getDeep(&Entry{
-		Partials: partials,
-		Raw:      v,
-	}, parts[1])
 }
 
 // LogrusErrorFinder finds logrus error in the JSON log and returns it as a LogrusError.
@@ -5,5 +5,5 @@ go 1.12
 require (
 	github.com/mattn/go-isatty v0.0.6
 	github.com/stretchr/testify v1.3.0
-	golang.org/x/sys v0.0.0-20190302025703-b6889370fb10 // indirect
+	golang.org/x/sys v0.0.0-20190302025703-b6889370fb10
 )
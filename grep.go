@@ -0,0 +1,174 @@
+package jl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sync"
+)
+
+// GrepSeparator is printed by GrepPrinter between groups of entries that are not contiguous in the input.
+const GrepSeparator = "--"
+
+// Grep selects log entries by matching a regular expression against them. It is also a Transformer that highlights
+// matches in formatted fields, so it can be used as a printer's Highlighter.
+type Grep struct {
+	// Pattern is the regular expression to search for.
+	Pattern *regexp.Regexp
+	// Fields restricts matching to the fields at these paths, located with ByNames. If empty, the pattern is matched
+	// against the raw log line. Entries that are not JSON are always matched against the raw line.
+	Fields []string
+}
+
+// NewGrep compiles pattern and returns a Grep that matches it against the given fields.
+func NewGrep(pattern string, fields ...string) (*Grep, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return &Grep{Pattern: re, Fields: fields}, nil
+}
+
+// Match reports whether the entry matches the pattern.
+func (g *Grep) Match(entry *Entry) bool {
+	if len(g.Fields) == 0 || entry.Partials == nil {
+		return g.Pattern.Match(entry.Raw)
+	}
+	for _, name := range g.Fields {
+		v := ByNames(name)(entry)
+		if v == nil {
+			continue
+		}
+		if g.Pattern.MatchString(DefaultStringer(&Context{}, v)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Transform highlights matches of the pattern in the input. If Fields is set, only those fields are highlighted: the
+// field named by the context, or the field whose value is that of one of Fields, like the "message" field of the
+// compact printer when matching "msg". Color escape sequences already present in the input are left intact.
+func (g *Grep) Transform(ctx *Context, input string) string {
+	if ctx.DisableColor || ctx.ColorMode == ColorModeNone || !g.highlights(ctx) {
+		return input
+	}
+	buf := &bytes.Buffer{}
+	start := 0
+	for _, loc := range ansiEscape.FindAllStringIndex(input, -1) {
+		g.highlight(buf, input[start:loc[0]])
+		buf.WriteString(input[loc[0]:loc[1]])
+		start = loc[1]
+	}
+	g.highlight(buf, input[start:])
+	return buf.String()
+}
+
+func (g *Grep) highlights(ctx *Context) bool {
+	if len(g.Fields) == 0 {
+		return true
+	}
+	value, _ := ctx.Value.(json.RawMessage)
+	for _, name := range g.Fields {
+		if name == ctx.Field {
+			return true
+		}
+		if value != nil && ctx.Entry != nil && ctx.Entry.Partials != nil {
+			if v, ok := ByNames(name)(ctx.Entry).(json.RawMessage); ok && bytes.Equal(v, value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// highlight writes text to buf, wrapping each match in reverse video. Reverse video is switched off on its own
+// rather than with a full reset so that the color of the surrounding field is preserved.
+func (g *Grep) highlight(buf *bytes.Buffer, text string) {
+	start := 0
+	for _, loc := range g.Pattern.FindAllStringIndex(text, -1) {
+		if loc[0] == loc[1] {
+			continue
+		}
+		buf.WriteString(text[start:loc[0]])
+		buf.WriteString("\x1b[7m")
+		buf.WriteString(text[loc[0]:loc[1]])
+		buf.WriteString("\x1b[27m")
+		start = loc[1]
+	}
+	buf.WriteString(text[start:])
+}
+
+// GrepPrinter prints only the entries selected by Grep, along with Before and After entries of surrounding context,
+// similar to grep -B and -A. When printing context, groups of entries that are not contiguous in the input are
+// separated by GrepSeparator.
+type GrepPrinter struct {
+	// Out is the writer that separators are written to. It should be the same writer that Printer writes to.
+	Out io.Writer
+	// Printer prints the selected entries.
+	Printer EntryPrinter
+	// Grep selects the entries to print.
+	Grep *Grep
+	// Before is the number of entries to print before each match.
+	Before int
+	// After is the number of entries to print after each match.
+	After int
+
+	mu sync.Mutex
+	// context holds up to Before entries preceding the current one.
+	context []*Entry
+	// afterLeft is the number of entries still to be printed as context after the last match.
+	afterLeft int
+	// seq is the position of the current entry in the input, and printed the position of the last printed entry.
+	seq, printed int
+}
+
+// NewGrepPrinter allocates and returns a new GrepPrinter that prints matching entries to printer.
+func NewGrepPrinter(w io.Writer, printer EntryPrinter, grep *Grep) *GrepPrinter {
+	return &GrepPrinter{
+		Out:     w,
+		Printer: printer,
+		Grep:    grep,
+	}
+}
+
+func (p *GrepPrinter) Print(entry *Entry) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.seq++
+	if p.Grep.Match(entry) {
+		first := p.seq - len(p.context)
+		if (p.Before > 0 || p.After > 0) && p.printed > 0 && first > p.printed+1 {
+			fmt.Fprintln(p.Out, GrepSeparator)
+		}
+		for _, e := range p.context {
+			p.Printer.Print(e)
+		}
+		p.context = p.context[:0]
+		p.Printer.Print(entry)
+		p.printed = p.seq
+		p.afterLeft = p.After
+		return
+	}
+	if p.afterLeft > 0 {
+		p.afterLeft--
+		p.Printer.Print(entry)
+		p.printed = p.seq
+		return
+	}
+	if p.Before > 0 {
+		if len(p.context) == p.Before {
+			copy(p.context, p.context[1:])
+			p.context = p.context[:len(p.context)-1]
+		}
+		p.context = append(p.context, entry)
+	}
+}
+
+func (p *GrepPrinter) Flush() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	Flush(p.Printer)
+}
@@ -0,0 +1,97 @@
+package jl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGrepPrinter_Print(t *testing.T) {
+	tests := []struct {
+		name      string
+		before    int
+		after     int
+		formatted string
+	}{{
+		name:      "no context",
+		formatted: "fixing truck 2\nfixing truck 6\nfixing truck 2\n",
+	}, {
+		name:   "context",
+		before: 1,
+		after:  1,
+		formatted: "fixing truck 1\nfixing truck 2\nfixing truck 3\n--\nfixing truck 5\nfixing truck 6\nfixing truck 2\n" +
+			"fixing truck 8\n",
+	}, {
+		name:      "overlapping context",
+		before:    3,
+		formatted: "fixing truck 0\nfixing truck 1\nfixing truck 2\nfixing truck 3\nfixing truck 4\nfixing truck 5\nfixing truck 6\nfixing truck 2\n",
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			grep, err := NewGrep("truck [26]")
+			require.NoError(t, err)
+			printer := NewGrepPrinter(buf, NewCompactPrinter(buf), grep)
+			printer.Before, printer.After = test.before, test.after
+			for _, n := range []int{0, 1, 2, 3, 4, 5, 6, 2, 8, 9} {
+				printer.Print(&Entry{Raw: []byte(fmt.Sprintf("fixing truck %d", n))})
+			}
+			assert.Equal(t, test.formatted, buf.String())
+		})
+	}
+}
+
+func TestGrep_MatchFields(t *testing.T) {
+	grep, err := NewGrep("truck 5", "message")
+	require.NoError(t, err)
+	entry := &Entry{Raw: []byte(`{"message":"fixed truck 5","logger":"truck 6"}`)}
+	require.NoError(t, json.Unmarshal(entry.Raw, &entry.Partials))
+	assert.True(t, grep.Match(entry))
+	grep.Fields = []string{"logger"}
+	assert.False(t, grep.Match(entry))
+}
+
+func TestGrep_MatchPaths(t *testing.T) {
+	grep, err := NewGrep("truck 5", "spans[*].name")
+	require.NoError(t, err)
+	entry := &Entry{Raw: []byte(`{"spans":[{"name":"load"},{"name":"truck 5"}]}`)}
+	require.NoError(t, json.Unmarshal(entry.Raw, &entry.Partials))
+	assert.True(t, grep.Match(entry))
+}
+
+func TestGrep_Transform(t *testing.T) {
+	grep, err := NewGrep("truck 5", "message")
+	require.NoError(t, err)
+	ctx := &Context{Field: "message"}
+	assert.Equal(t, "\x1b[32mfixed \x1b[7mtruck 5\x1b[27m!\x1b[0m", grep.Transform(ctx, ColorText(Green, "fixed truck 5!")))
+	ctx = &Context{Field: "logger"}
+	assert.Equal(t, "truck 5", grep.Transform(ctx, "truck 5"))
+}
+
+func TestGrep_TransformFoundField(t *testing.T) {
+	grep, err := NewGrep("truck 5", "msg")
+	require.NoError(t, err)
+	entry := &Entry{Raw: []byte(`{"level":"info","msg":"fixed truck 5","logger":"truck 5"}`)}
+	require.NoError(t, json.Unmarshal(entry.Raw, &entry.Partials))
+	buf := &bytes.Buffer{}
+	printer := NewCompactPrinter(buf)
+	printer.ColorMode = ColorMode16
+	printer.FieldFormats = []FieldFmt{
+		{Name: "logger", Finders: []FieldFinder{ByNames("logger")}},
+		{Name: "message", Finders: []FieldFinder{MessageFinder}},
+	}
+	printer.Highlighter = grep
+	printer.Print(entry)
+	assert.Equal(t, "truck 5 fixed \x1b[7mtruck 5\x1b[27m\n", buf.String())
+}
+
+func TestGrep_MatchRaw(t *testing.T) {
+	grep, err := NewGrep("broken axle")
+	require.NoError(t, err)
+	assert.True(t, grep.Match(&Entry{Raw: []byte("panic: broken axle")}))
+	assert.False(t, grep.Match(&Entry{Raw: []byte("all good")}))
+}
@@ -0,0 +1,318 @@
+package jl
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultHistogramBucket is the default HistogramPrinter.Bucket.
+	DefaultHistogramBucket = time.Minute
+	// DefaultHistogramWidth is the default HistogramPrinter.Width.
+	DefaultHistogramWidth = 50
+	// DefaultHistogramWindow is the default HistogramPrinter.Window.
+	DefaultHistogramWindow = 3
+	// maxEmptyBuckets is the number of consecutive empty buckets printed before they are summarized in a single line.
+	maxEmptyBuckets = 3
+	// maxGapBuckets is the number of empty buckets that a gap adds to the sparklines at most, so that an entry with a
+	// timestamp far from the others does not allocate a bucket for every interval in between.
+	maxGapBuckets = 1000
+)
+
+// histogramLevels are the levels that bars are stacked by, from the left. Levels not in the list, and entries without
+// a level, are stacked last.
+var histogramLevels = []string{LevelFatal, LevelError, LevelWarn, LevelInfo, LevelDebug, LevelTrace}
+
+// histogramBlocks are the characters that bar segments are drawn with for each level, so that levels can be told
+// apart without color.
+var histogramBlocks = map[string]string{
+	LevelFatal: "█",
+	LevelError: "█",
+	LevelWarn:  "▓",
+	LevelInfo:  "▒",
+	LevelDebug: "░",
+	LevelTrace: "░",
+}
+
+// sparkBlocks are the characters of a sparkline, from lowest to highest.
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// HistogramPrinter counts entries per time bucket, using the timestamp found by TimestampFinder, and prints a bar for
+// each bucket stacked by level. The most recent buckets are kept open for entries that arrive out of order, and a
+// bucket is printed once it falls out of that window, so the histogram can be followed on a stream. When flushed, the last bucket is printed followed by sparklines of all entries and of
+// errors.
+type HistogramPrinter struct {
+	// Out is the writer where the histogram is written to.
+	Out io.Writer
+	// Bucket is the length of time counted by each bar.
+	Bucket time.Duration
+	// Width is the number of columns of the longest bar. Bars are scaled to the busiest bucket seen so far.
+	Width int
+	// Window is the number of most recent buckets kept open for late entries. Entries from buckets that were already
+	// printed are skipped.
+	Window int
+	// DisableColor disables ANSI escape sequences.
+	DisableColor bool
+	// ColorMode is the color mode of the terminal. Colors that it does not support are downgraded.
+	ColorMode ColorMode
+	// Theme sets the colors of the levels. If nil, DefaultTheme is used.
+	Theme *Theme
+
+	mu sync.Mutex
+	// open are the buckets not printed yet, in order.
+	open []*histogramBucket
+	// printed is the start of the last bucket printed.
+	printed time.Time
+	max     int
+	totals  []int
+	errors  []int
+	late    int
+	untimed int
+}
+
+type histogramBucket struct {
+	start  time.Time
+	counts map[string]int
+	total  int
+}
+
+// NewHistogramPrinter allocates and returns a new HistogramPrinter.
+func NewHistogramPrinter(w io.Writer) *HistogramPrinter {
+	return &HistogramPrinter{
+		Out:    w,
+		Bucket: DefaultHistogramBucket,
+		Width:  DefaultHistogramWidth,
+		Window: DefaultHistogramWindow,
+	}
+}
+
+func (p *HistogramPrinter) Print(entry *Entry) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ts, ok := EntryTime(entry)
+	if !ok {
+		p.untimed++
+		return
+	}
+	start := ts.Truncate(p.Bucket)
+	if !p.printed.IsZero() && !start.After(p.printed) {
+		p.late++
+		return
+	}
+	i := len(p.open)
+	for i > 0 && p.open[i-1].start.After(start) {
+		i--
+	}
+	if i > 0 && p.open[i-1].start.Equal(start) {
+		p.open[i-1].add(EntryLevel(entry))
+		return
+	}
+	b := &histogramBucket{start: start, counts: make(map[string]int)}
+	b.add(EntryLevel(entry))
+	p.open = append(p.open, nil)
+	copy(p.open[i+1:], p.open[i:])
+	p.open[i] = b
+
+	// Print the buckets that fell out of the window.
+	window := p.Window
+	if window < 1 {
+		window = 1
+	}
+	cutoff := p.open[len(p.open)-1].start.Add(-time.Duration(window-1) * p.Bucket)
+	for len(p.open) > 0 && p.open[0].start.Before(cutoff) {
+		p.printOpen()
+	}
+}
+
+// printOpen prints the oldest open bucket, after the empty buckets between it and the last one printed.
+func (p *HistogramPrinter) printOpen() {
+	b := p.open[0]
+	p.open = p.open[1:]
+	if !p.printed.IsZero() {
+		p.printGap(p.printed, b.start)
+	}
+	p.printBucket(b)
+	p.printed = b.start
+}
+
+// Flush prints the last bucket, and the sparklines and counts of the whole histogram.
+func (p *HistogramPrinter) Flush() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for len(p.open) > 0 {
+		p.printOpen()
+	}
+	if len(p.totals) > 1 {
+		spark := func(values []int) string {
+			return strings.TrimRight(Sparkline(downsample(values, p.Width)), " ")
+		}
+		fmt.Fprintf(p.Out, "\n%s %s\n", p.label("all"), spark(p.totals))
+		fmt.Fprintf(p.Out, "%s %s\n", p.label("errors"), p.render(LevelError, spark(p.errors)))
+	}
+	if p.untimed > 0 {
+		fmt.Fprintf(p.Out, "%s without a timestamp\n", pluralize(p.untimed, "entry", "entries"))
+	}
+	if p.late > 0 {
+		fmt.Fprintf(p.Out, "skipped %s older than the buckets being counted\n", pluralize(p.late, "entry", "entries"))
+	}
+}
+
+func (b *histogramBucket) add(level string) {
+	b.counts[level]++
+	b.total++
+}
+
+func (p *HistogramPrinter) printBucket(b *histogramBucket) {
+	if b.total > p.max {
+		p.max = b.total
+	}
+	p.totals = append(p.totals, b.total)
+	p.errors = append(p.errors, b.counts[LevelError]+b.counts[LevelFatal])
+
+	var bar strings.Builder
+	var details []string
+	drawn, cumulative := 0, 0
+	for _, level := range p.stackOrder(b) {
+		n := b.counts[level]
+		cumulative += n
+		// Round the cumulative width so segments add up to the width of the whole bar, but make sure that every level
+		// present gets at least one column so a single error is not lost in a busy bucket.
+		cells := cumulative*p.Width/p.max - drawn
+		if cells < 1 {
+			cells = 1
+		}
+		drawn += cells
+		block, ok := histogramBlocks[level]
+		if !ok {
+			block = "░"
+		}
+		bar.WriteString(p.render(level, strings.Repeat(block, cells)))
+		if level != "" {
+			details = append(details, fmt.Sprintf("%s %d", strings.ToUpper(level), n))
+		}
+	}
+	padding := ""
+	if drawn < p.Width {
+		padding = strings.Repeat(" ", p.Width-drawn)
+	}
+	line := fmt.Sprintf("%s %s%s %6d", p.timeLabel(b.start), bar.String(), padding, b.total)
+	if len(details) > 0 {
+		line += "  " + strings.Join(details, " · ")
+	}
+	fmt.Fprintln(p.Out, line)
+}
+
+// printGap prints the empty buckets between the bucket starting at from and the one starting at to. Long gaps are
+// summarized in one line.
+func (p *HistogramPrinter) printGap(from, to time.Time) {
+	empty := int(to.Sub(from)/p.Bucket) - 1
+	if empty <= 0 {
+		return
+	}
+	for i := 0; i < empty && i < maxGapBuckets; i++ {
+		p.totals = append(p.totals, 0)
+		p.errors = append(p.errors, 0)
+	}
+	if empty > maxEmptyBuckets {
+		fmt.Fprintf(p.Out, "%s %s, %s without entries\n", p.label("⋮"), pluralize(empty, "empty bucket", "empty buckets"),
+			to.Sub(from.Add(p.Bucket)))
+		return
+	}
+	for i := 1; i <= empty; i++ {
+		fmt.Fprintf(p.Out, "%s %s %6d\n", p.timeLabel(from.Add(time.Duration(i)*p.Bucket)), strings.Repeat(" ", p.Width), 0)
+	}
+}
+
+// stackOrder returns the levels of the bucket in the order they are stacked.
+func (p *HistogramPrinter) stackOrder(b *histogramBucket) []string {
+	var levels []string
+	for _, level := range histogramLevels {
+		if b.counts[level] > 0 {
+			levels = append(levels, level)
+		}
+	}
+	others := make(map[string]int)
+	for level, n := range b.counts {
+		if !isKnownLevel(level) {
+			others[level] = n
+		}
+	}
+	// sortLevels puts the empty level, for entries without one, first among the unknown levels.
+	return append(levels, sortLevels(others)...)
+}
+
+func (p *HistogramPrinter) render(level, text string) string {
+	if p.DisableColor {
+		return text
+	}
+	theme := p.Theme
+	if theme == nil {
+		theme = DefaultTheme
+	}
+	style, ok := theme.Levels[level]
+	if !ok {
+		return text
+	}
+	return style.Render(p.ColorMode, text)
+}
+
+// timeLayout returns the layout that bucket start times are printed with, with just enough precision for the bucket.
+func (p *HistogramPrinter) timeLayout() string {
+	switch {
+	case p.Bucket >= 24*time.Hour:
+		return "2006-01-02"
+	case p.Bucket >= time.Second:
+		return "2006-01-02 15:04:05"
+	default:
+		return "2006-01-02 15:04:05.000"
+	}
+}
+
+func (p *HistogramPrinter) timeLabel(t time.Time) string {
+	return t.Format(p.timeLayout())
+}
+
+// label pads text to the width of the time labels.
+func (p *HistogramPrinter) label(text string) string {
+	return text + strings.Repeat(" ", len(p.timeLayout())-DisplayWidth(text))
+}
+
+// Sparkline draws the values as a line of block characters of increasing height, scaled so that the largest value is
+// a full block. Zeros are drawn as spaces.
+func Sparkline(values []int) string {
+	max := 0
+	for _, v := range values {
+		if v > max {
+			max = v
+		}
+	}
+	var spark strings.Builder
+	for _, v := range values {
+		if v <= 0 {
+			spark.WriteRune(' ')
+			continue
+		}
+		spark.WriteRune(sparkBlocks[(v*len(sparkBlocks)-1)/max])
+	}
+	return spark.String()
+}
+
+// downsample sums adjacent values so that there are no more than n of them.
+func downsample(values []int, n int) []int {
+	if n <= 0 || len(values) <= n {
+		return values
+	}
+	size := (len(values) + n - 1) / n
+	var sums []int
+	for i := 0; i < len(values); i += size {
+		sum := 0
+		for j := i; j < i+size && j < len(values); j++ {
+			sum += values[j]
+		}
+		sums = append(sums, sum)
+	}
+	return sums
+}
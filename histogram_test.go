@@ -0,0 +1,98 @@
+package jl
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistogramPrinter_Print(t *testing.T) {
+	logs := `{"timestamp":"2019-01-01T15:00:10Z","level":"info"}
+{"timestamp":"2019-01-01T15:00:20Z","level":"info"}
+{"timestamp":"2019-01-01T15:00:30Z","level":"error"}
+{"timestamp":"2019-01-01T15:00:40Z","level":"info"}
+{"timestamp":"2019-01-01T15:02:10Z","level":"warn"}
+{"timestamp":"2019-01-01T15:01:10Z","level":"info"}
+{"timestamp":"2019-01-01T15:10:10Z","level":"info"}
+{"timestamp":"2019-01-01T15:10:20Z"}
+{"timestamp":"2019-01-01T14:50:00Z","level":"info"}
+not json`
+	buf := &bytes.Buffer{}
+	printer := NewHistogramPrinter(buf)
+	printer.Width = 8
+	printer.DisableColor = true
+	for _, entry := range parseLines(t, logs) {
+		printer.Print(entry)
+	}
+	printer.Flush()
+	assert.Equal(t, `2019-01-01 15:00:00 ██▒▒▒▒▒▒      4  ERROR 1 · INFO 3
+2019-01-01 15:01:00 ▒▒            1  INFO 1
+2019-01-01 15:02:00 ▓▓            1  WARN 1
+⋮                   7 empty buckets, 7m0s without entries
+2019-01-01 15:10:00 ▒▒░░          2  INFO 1
+
+all                 █▂   ▄
+errors              █
+1 entry without a timestamp
+skipped 1 entry older than the buckets being counted
+`, buf.String())
+}
+
+func TestHistogramPrinter_LongGap(t *testing.T) {
+	logs := `{"timestamp":"1970-01-01T00:00:00Z","level":"info"}
+{"timestamp":"2019-01-01T15:00:00Z","level":"info"}`
+	buf := &bytes.Buffer{}
+	printer := NewHistogramPrinter(buf)
+	printer.Bucket = time.Second
+	printer.Width = 4
+	printer.DisableColor = true
+	for _, entry := range parseLines(t, logs) {
+		printer.Print(entry)
+	}
+	printer.Flush()
+	assert.Len(t, printer.totals, 1+maxGapBuckets+1)
+	assert.Contains(t, buf.String(), `1970-01-01 00:00:00 ▒▒▒▒      1  INFO 1
+⋮                   1546354799 empty buckets, 429542h59m59s without entries
+2019-01-01 15:00:00 ▒▒▒▒      1  INFO 1
+`)
+}
+
+func TestHistogramPrinter_Window(t *testing.T) {
+	logs := `{"timestamp":"2019-01-01T15:00:10Z","level":"info"}
+{"timestamp":"2019-01-01T15:01:10Z","level":"info"}
+{"timestamp":"2019-01-01T15:00:20Z","level":"error"}
+{"timestamp":"2019-01-01T15:02:10Z","level":"info"}
+{"timestamp":"2019-01-01T15:00:30Z","level":"info"}
+{"timestamp":"2019-01-01T15:03:10Z","level":"info"}
+{"timestamp":"2019-01-01T15:00:40Z","level":"info"}
+{"timestamp":"2019-01-01T15:01:20Z","level":"info"}`
+	buf := &bytes.Buffer{}
+	printer := NewHistogramPrinter(buf)
+	printer.Width = 8
+	printer.DisableColor = true
+	for i, entry := range parseLines(t, logs) {
+		printer.Print(entry)
+		if i == 5 {
+			// 15:03 pushed 15:00 out of the window, so it is printed and later entries for it are skipped.
+			assert.Equal(t, "2019-01-01 15:00:00 ██▒▒▒▒▒▒      3  ERROR 1 · INFO 2\n", buf.String())
+		}
+	}
+	printer.Flush()
+	assert.Equal(t, `2019-01-01 15:00:00 ██▒▒▒▒▒▒      3  ERROR 1 · INFO 2
+2019-01-01 15:01:00 ▒▒▒▒▒         2  INFO 2
+2019-01-01 15:02:00 ▒▒            1  INFO 1
+2019-01-01 15:03:00 ▒▒            1  INFO 1
+
+all                 █▆▃▃
+errors              █
+skipped 1 entry older than the buckets being counted
+`, buf.String())
+}
+
+func TestSparkline(t *testing.T) {
+	assert.Equal(t, "▁ ▄█", Sparkline([]int{1, 0, 4, 8}))
+	assert.Equal(t, "  ", Sparkline([]int{0, 0}))
+	assert.Equal(t, []int{3, 7, 5}, downsample([]int{1, 2, 3, 4, 5}, 3))
+}
@@ -0,0 +1,292 @@
+package jl
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxIngestLineSize is the size of the longest line accepted by IngestHandler.
+const maxIngestLineSize = 1024 * 1024
+
+// IngestHandler is an http.Handler that accepts logs in the formats of common log backends, so that services can ship
+// their logs to jl during development. It accepts:
+//
+//   - Loki pushes in JSON, to /loki/api/v1/push
+//   - Elasticsearch bulk requests, to /_bulk and /<index>/_bulk
+//   - Newline-delimited JSON, POSTed to any other path
+//
+// Request bodies may be gzipped. Each record is printed with Printer, tagged with the address of the client, and the
+// request is answered the way the backend would.
+type IngestHandler struct {
+	// Printer prints the received entries. Requests are handled concurrently, so it must be safe for concurrent use.
+	Printer EntryPrinter
+	// Decoder decodes the received lines and documents. If nil, they are decoded as JSON. Calls to it are serialized,
+	// and the lines it holds on to are drained after each request.
+	Decoder Decoder
+
+	mu sync.Mutex
+}
+
+// NewIngestHandler allocates and returns a new IngestHandler.
+func NewIngestHandler(printer EntryPrinter) *IngestHandler {
+	return &IngestHandler{
+		Printer: printer,
+	}
+}
+
+func (h *IngestHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet && r.URL.Path == "/" {
+		// Elasticsearch clients check the version of the cluster before sending requests.
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"name":    "jl",
+			"tagline": "You Know, for Search",
+			"version": map[string]string{"number": "7.10.2"},
+		})
+		return
+	}
+	if r.Method != http.MethodPost && r.Method != http.MethodPut {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := requestBody(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer body.Close()
+	switch {
+	case r.URL.Path == "/loki/api/v1/push":
+		h.serveLoki(w, r, body)
+	case r.URL.Path == "/_bulk" || strings.HasSuffix(r.URL.Path, "/_bulk"):
+		h.serveBulk(w, r, body)
+	default:
+		h.serveNDJSON(w, r, body)
+	}
+	h.drain(r)
+}
+
+// requestBody returns the body of the request, decompressed if it is gzipped.
+func requestBody(r *http.Request) (io.ReadCloser, error) {
+	if r.Header.Get("Content-Encoding") != "gzip" {
+		return r.Body, nil
+	}
+	return gzip.NewReader(r.Body)
+}
+
+// decode decodes a line with the Decoder.
+func (h *IngestHandler) decode(line []byte) (*Entry, bool) {
+	if h.Decoder == nil {
+		return JSONDecoder.Decode(line)
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.Decoder.Decode(line)
+}
+
+// drain prints the entries of the lines held by the Decoder.
+func (h *IngestHandler) drain(r *http.Request) {
+	drainer, ok := h.Decoder.(Drainer)
+	if !ok {
+		return
+	}
+	h.mu.Lock()
+	entries := drainer.Drain()
+	h.mu.Unlock()
+	for _, entry := range entries {
+		h.printEntry(r, entry)
+	}
+}
+
+// print decodes the line and prints it. Lines that are not decoded are printed as is.
+func (h *IngestHandler) print(r *http.Request, line []byte) {
+	entry, ok := h.decode(line)
+	if !ok {
+		entry = &Entry{Raw: line}
+	}
+	if entry != nil {
+		h.printEntry(r, entry)
+	}
+}
+
+func (h *IngestHandler) printEntry(r *http.Request, entry *Entry) {
+	entry.Source = r.RemoteAddr
+	h.Printer.Print(entry)
+}
+
+type lokiPush struct {
+	Streams []struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	} `json:"streams"`
+}
+
+// serveLoki handles a Loki push in JSON. Lines that are not decoded are printed as objects with the timestamp, the
+// stream's labels and the line as the message.
+func (h *IngestHandler) serveLoki(w http.ResponseWriter, r *http.Request, body io.Reader) {
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-protobuf") {
+		http.Error(w, "only JSON pushes are supported", http.StatusUnsupportedMediaType)
+		return
+	}
+	var push lokiPush
+	if err := json.NewDecoder(body).Decode(&push); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for _, stream := range push.Streams {
+		labels := make([]string, 0, len(stream.Stream))
+		for k := range stream.Stream {
+			labels = append(labels, k)
+		}
+		sort.Strings(labels)
+		for _, value := range stream.Values {
+			line := value[1]
+			if entry, ok := h.decode([]byte(line)); ok {
+				if entry != nil {
+					h.printEntry(r, entry)
+				}
+				continue
+			}
+			obj := make(map[string]json.RawMessage)
+			if ns, err := strconv.ParseInt(value[0], 10, 64); err == nil {
+				obj["timestamp"] = marshalString(time.Unix(0, ns).Format(time.RFC3339Nano))
+			}
+			for _, k := range labels {
+				obj[k] = marshalString(stream.Stream[k])
+			}
+			obj["message"] = marshalString(line)
+			h.printEntry(r, NewEntry(marshalRaw(obj)))
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// serveBulk handles an Elasticsearch bulk request: pairs of lines with an action and a document, except for deletes
+// which have no document. The documents that are indexed, created or updated are printed.
+func (h *IngestHandler) serveBulk(w http.ResponseWriter, r *http.Request, body io.Reader) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(nil, maxIngestLineSize)
+	var items []map[string]interface{}
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var action map[string]struct {
+			Index string `json:"_index"`
+			// ID is echoed back as is, since clients may send numbers.
+			ID json.RawMessage `json:"_id"`
+		}
+		if err := json.Unmarshal(line, &action); err != nil || len(action) != 1 {
+			http.Error(w, fmt.Sprintf("invalid bulk action: %s", line), http.StatusBadRequest)
+			return
+		}
+		for name, meta := range action {
+			var id interface{} = meta.ID
+			if meta.ID == nil {
+				id = ""
+			}
+			status, result := http.StatusCreated, "created"
+			switch name {
+			case "delete":
+				status, result = http.StatusOK, "deleted"
+			case "index", "create", "update":
+				if !scanner.Scan() {
+					http.Error(w, "bulk action without a document", http.StatusBadRequest)
+					return
+				}
+				doc := append([]byte(nil), scanner.Bytes()...)
+				if name == "update" {
+					status, result = http.StatusOK, "updated"
+					var update struct {
+						Doc json.RawMessage `json:"doc"`
+					}
+					if err := json.Unmarshal(doc, &update); err == nil && update.Doc != nil {
+						doc = update.Doc
+					}
+				}
+				h.print(r, doc)
+			default:
+				http.Error(w, fmt.Sprintf("unknown bulk action %q", name), http.StatusBadRequest)
+				return
+			}
+			items = append(items, map[string]interface{}{
+				name: map[string]interface{}{
+					"_index": meta.Index,
+					"_id":    id,
+					"status": status,
+					"result": result,
+				},
+			})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"took":   0,
+		"errors": false,
+		"items":  items,
+	})
+}
+
+// serveNDJSON handles a body of newline-delimited JSON, or a JSON array of entries. Lines that are not JSON are printed
+// as is.
+func (h *IngestHandler) serveNDJSON(w http.ResponseWriter, r *http.Request, body io.Reader) {
+	br := bufio.NewReader(body)
+	if first, err := peekNonSpace(br); err == nil && first == '[' {
+		var entries []json.RawMessage
+		if err := json.NewDecoder(br).Decode(&entries); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for _, entry := range entries {
+			h.print(r, entry)
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	scanner := bufio.NewScanner(br)
+	scanner.Buffer(nil, maxIngestLineSize)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		h.print(r, append([]byte(nil), scanner.Bytes()...))
+	}
+	if err := scanner.Err(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// peekNonSpace skips leading white space, and returns the next byte without consuming it.
+func peekNonSpace(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+		if b[0] != ' ' && b[0] != '\t' && b[0] != '\r' && b[0] != '\n' {
+			return b[0], nil
+		}
+		br.Discard(1)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
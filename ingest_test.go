@@ -0,0 +1,109 @@
+package jl
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rawPrinter records the entries it prints, and their raw lines.
+type rawPrinter struct {
+	entries []*Entry
+	lines   []string
+}
+
+func (p *rawPrinter) Print(entry *Entry) {
+	p.entries = append(p.entries, entry)
+	p.lines = append(p.lines, string(entry.Raw))
+}
+
+func TestIngestHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		body     string
+		status   int
+		response string
+		lines    []string
+	}{{
+		name:   "loki",
+		path:   "/loki/api/v1/push",
+		body:   `{"streams":[{"stream":{"app":"api"},"values":[["0","plain"],["0","{\"msg\":\"json\"}"]]}]}`,
+		status: http.StatusNoContent,
+		lines:  []string{`{"app":"api","message":"plain","timestamp":"` + timeRFC3339(0) + `"}`, `{"msg":"json"}`},
+	}, {
+		name:     "bulk",
+		path:     "/logs/_bulk",
+		body:     "{\"index\":{}}\n{\"msg\":\"a\"}\n{\"delete\":{\"_id\":1}}\n{\"update\":{\"_id\":\"2\"}}\n{\"doc\":{\"msg\":\"b\"}}\n",
+		status:   http.StatusOK,
+		response: `{"errors":false,"items":[{"index":{"_id":"","_index":"","result":"created","status":201}},{"delete":{"_id":1,"_index":"","result":"deleted","status":200}},{"update":{"_id":"2","_index":"","result":"updated","status":200}}],"took":0}` + "\n",
+		lines:    []string{`{"msg":"a"}`, `{"msg":"b"}`},
+	}, {
+		name:   "ndjson",
+		path:   "/",
+		body:   "{\"msg\":\"a\"}\n\nnot json\n",
+		status: http.StatusNoContent,
+		lines:  []string{`{"msg":"a"}`, "not json"},
+	}, {
+		name:   "array",
+		path:   "/logs",
+		body:   ` [{"msg":"a"}, {"msg":"b"}]`,
+		status: http.StatusNoContent,
+		lines:  []string{`{"msg":"a"}`, `{"msg":"b"}`},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			printer := &rawPrinter{}
+			w := httptest.NewRecorder()
+			NewIngestHandler(printer).ServeHTTP(w, httptest.NewRequest(http.MethodPost, test.path, strings.NewReader(test.body)))
+			assert.Equal(t, test.status, w.Code)
+			if test.response != "" {
+				assert.Equal(t, test.response, w.Body.String())
+			}
+			assert.Equal(t, test.lines, printer.lines)
+		})
+	}
+}
+
+func TestIngestHandler_Gzip(t *testing.T) {
+	var body bytes.Buffer
+	gz := gzip.NewWriter(&body)
+	gz.Write([]byte(`{"msg":"a"}`))
+	require.NoError(t, gz.Close())
+
+	printer := &rawPrinter{}
+	r := httptest.NewRequest(http.MethodPost, "/", &body)
+	r.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	NewIngestHandler(printer).ServeHTTP(w, r)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, []string{`{"msg":"a"}`}, printer.lines)
+}
+
+func TestIngestHandler_Decoder(t *testing.T) {
+	printer := &rawPrinter{}
+	h := NewIngestHandler(printer)
+	h.Decoder = Decoders["logfmt"]()
+	body := "level=info msg=a\n{\"msg\":\"b\"}\nnot logfmt\n"
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	entries := printer.entries
+	require.Len(t, entries, 3)
+	assert.Equal(t, "level=info msg=a", string(entries[0].Raw))
+	assert.JSONEq(t, `{"level":"info","msg":"a"}`, string(marshalRaw(entries[0].Partials)))
+	assert.JSONEq(t, `{"msg":"b"}`, string(marshalRaw(entries[1].Partials)))
+	assert.Nil(t, entries[2].Partials)
+	assert.Equal(t, "not logfmt", string(entries[2].Raw))
+}
+
+func timeRFC3339(ns int64) string {
+	return time.Unix(0, ns).Format(time.RFC3339Nano)
+}
@@ -0,0 +1,75 @@
+//go:build go1.14
+// +build go1.14
+
+// Package jltest prints the logs of tests with jl's compact format. The logs are buffered, and only printed with the
+// output of the test that wrote them if it fails.
+//
+//	func TestServer(t *testing.T) {
+//		srv := NewServer(Config{LogOutput: jltest.NewWriter(t)})
+//		...
+//	}
+package jltest
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/mightyguava/jl"
+)
+
+// NewWriter returns a writer that formats the JSON logs written to it, and prints them with t.Log when the test
+// finishes if it failed. It is safe for concurrent use, and writes after the test finished are discarded.
+func NewWriter(t testing.TB) io.Writer {
+	buf := &logBuffer{}
+	w := jl.NewWriter(newPrinter(buf))
+	t.Cleanup(func() {
+		w.Close()
+		buf.report(t)
+	})
+	return discardClosed{w}
+}
+
+// discardClosed discards writes to a closed writer, since goroutines may still be logging after the test finished.
+type discardClosed struct {
+	io.Writer
+}
+
+func (w discardClosed) Write(p []byte) (int, error) {
+	n, err := w.Writer.Write(p)
+	if err == io.ErrClosedPipe {
+		return len(p), nil
+	}
+	return n, err
+}
+
+// newPrinter returns the printer used to format the logs of tests.
+func newPrinter(w io.Writer) jl.EntryPrinter {
+	printer := jl.NewCompactPrinter(w)
+	printer.DisableColor = true
+	return printer
+}
+
+// logBuffer holds the formatted logs of a test.
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// report prints the logs if the test failed.
+func (b *logBuffer) report(t testing.TB) {
+	t.Helper()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !t.Failed() || b.buf.Len() == 0 {
+		return
+	}
+	t.Log("logs:\n" + b.buf.String())
+}
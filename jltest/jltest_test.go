@@ -0,0 +1,65 @@
+//go:build go1.14
+// +build go1.14
+
+package jltest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTB records what is logged, and the cleanup functions registered, by a test.
+type fakeTB struct {
+	testing.TB
+	failed   bool
+	logs     []string
+	cleanups []func()
+}
+
+func (t *fakeTB) Helper()                 {}
+func (t *fakeTB) Failed() bool            { return t.failed }
+func (t *fakeTB) Cleanup(f func())        { t.cleanups = append(t.cleanups, f) }
+func (t *fakeTB) Log(args ...interface{}) { t.logs = append(t.logs, fmt.Sprint(args...)) }
+
+func (t *fakeTB) finish() {
+	for i := len(t.cleanups) - 1; i >= 0; i-- {
+		t.cleanups[i]()
+	}
+}
+
+func TestNewWriter(t *testing.T) {
+	tests := []struct {
+		name   string
+		failed bool
+		logs   []string
+	}{{
+		name:   "passed",
+		failed: false,
+	}, {
+		name:   "failed",
+		failed: true,
+		logs:   []string{"logs:\nINFO started\nERRO boom\n"},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tb := &fakeTB{failed: test.failed}
+			w := NewWriter(tb)
+			fmt.Fprintln(w, `{"level":"info","message":"started"}`)
+			fmt.Fprint(w, `{"level":"error","message":"boom"}`)
+			tb.finish()
+			assert.Equal(t, test.logs, tb.logs)
+		})
+	}
+}
+
+func TestNewWriter_AfterFinish(t *testing.T) {
+	tb := &fakeTB{failed: true}
+	w := NewWriter(tb)
+	tb.finish()
+	n, err := fmt.Fprintln(w, `{"level":"info","message":"late"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, 34, n)
+	assert.Empty(t, tb.logs)
+}
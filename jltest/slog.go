@@ -0,0 +1,24 @@
+//go:build go1.21
+// +build go1.21
+
+package jltest
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/mightyguava/jl"
+)
+
+// NewSlogHandler returns a slog handler that formats records, including debug records, and prints them with t.Log
+// when the test finishes if it failed.
+func NewSlogHandler(t testing.TB) *jl.SlogHandler {
+	buf := &logBuffer{}
+	h := jl.NewSlogHandler(buf)
+	h.Printer = newPrinter(buf)
+	h.Level = slog.LevelDebug
+	t.Cleanup(func() {
+		buf.report(t)
+	})
+	return h
+}
@@ -0,0 +1,20 @@
+//go:build go1.21
+// +build go1.21
+
+package jltest
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSlogHandler(t *testing.T) {
+	tb := &fakeTB{failed: true}
+	logger := slog.New(NewSlogHandler(tb))
+	logger.Debug("connecting", "logger", "db")
+	tb.finish()
+	assert.Len(t, tb.logs, 1)
+	assert.Regexp(t, `^logs:\nDEBU \S+ +db\| connecting\n$`, tb.logs[0])
+}
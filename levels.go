@@ -0,0 +1,91 @@
+package jl
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Normalized log levels returned by NormalizeLevel.
+const (
+	LevelTrace = "trace"
+	LevelDebug = "debug"
+	LevelInfo  = "info"
+	LevelWarn  = "warn"
+	LevelError = "error"
+	LevelFatal = "fatal"
+)
+
+// Levels lists the normalized log levels from least to most severe.
+var Levels = []string{LevelTrace, LevelDebug, LevelInfo, LevelWarn, LevelError, LevelFatal}
+
+// LevelFinder finds the log level of an entry, using the same field names as DefaultCompactPrinterFieldFmt.
+var LevelFinder = ByNames("level", "severity", "logLevel")
+
+// levelAliases maps the level names used by common logging libraries to normalized levels.
+var levelAliases = map[string]string{
+	"trace":       LevelTrace,
+	"finest":      LevelTrace,
+	"finer":       LevelTrace,
+	"debug":       LevelDebug,
+	"fine":        LevelDebug,
+	"dbg":         LevelDebug,
+	"info":        LevelInfo,
+	"information": LevelInfo,
+	"notice":      LevelInfo,
+	"log":         LevelInfo,
+	"default":     LevelInfo,
+	"config":      LevelInfo,
+	"warn":        LevelWarn,
+	"warning":     LevelWarn,
+	"wrn":         LevelWarn,
+	"error":       LevelError,
+	"err":         LevelError,
+	"severe":      LevelError,
+	"fatal":       LevelFatal,
+	"panic":       LevelFatal,
+	"critical":    LevelFatal,
+	"crit":        LevelFatal,
+	"alert":       LevelFatal,
+	"emergency":   LevelFatal,
+	"emerg":       LevelFatal,
+}
+
+// NormalizeLevel maps a log level to one of the levels in Levels. It understands the names used by common logging
+// libraries, and the numeric levels of bunyan and pino. Unknown levels are returned lower-cased.
+func NormalizeLevel(level string) string {
+	level = strings.ToLower(strings.TrimSpace(level))
+	if normalized, ok := levelAliases[level]; ok {
+		return normalized
+	}
+	if n, err := strconv.Atoi(level); err == nil {
+		switch {
+		case n <= 10:
+			return LevelTrace
+		case n <= 20:
+			return LevelDebug
+		case n <= 30:
+			return LevelInfo
+		case n <= 40:
+			return LevelWarn
+		case n <= 50:
+			return LevelError
+		default:
+			return LevelFatal
+		}
+	}
+	return level
+}
+
+// EntryLevel returns the normalized level of the entry, or "" if it has none.
+func EntryLevel(entry *Entry) string {
+	v := LevelFinder(entry)
+	if v == nil {
+		return ""
+	}
+	return NormalizeLevel(DefaultStringer(&Context{}, v))
+}
+
+// IsErrorLevel reports whether a normalized level is error or more severe.
+func IsErrorLevel(level string) bool {
+	return level == LevelError || level == LevelFatal
+}
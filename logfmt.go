@@ -0,0 +1,92 @@
+package jl
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// LogfmtDecoder decodes lines in the logfmt format, like those written by go-kit and Heroku:
+//
+//	level=info msg="hello world" dur=3ms
+//
+// A line is accepted only if every word in it is a key=value pair, so that plain text is left alone. Values are
+// decoded as strings, except for numbers and booleans. The entry's Raw is the original line.
+var LogfmtDecoder Decoder = DecoderFunc(decodeLogfmt)
+
+func decodeLogfmt(line []byte) (*Entry, bool) {
+	s := string(line)
+	values := make(map[string]json.RawMessage)
+	for i := 0; ; {
+		for i < len(s) && isLogfmtSpace(s[i]) {
+			i++
+		}
+		if i == len(s) {
+			break
+		}
+		start := i
+		for i < len(s) && s[i] > ' ' && s[i] != '=' && s[i] != '"' {
+			i++
+		}
+		if i == start || i == len(s) || s[i] != '=' {
+			return nil, false
+		}
+		key := s[start:i]
+		i++
+
+		var value json.RawMessage
+		if i < len(s) && s[i] == '"' {
+			end := i + 1
+			for end < len(s) && s[end] != '"' {
+				if s[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(s) || end+1 < len(s) && !isLogfmtSpace(s[end+1]) {
+				return nil, false
+			}
+			str, ok := unquoteLogfmt(s[i : end+1])
+			if !ok {
+				return nil, false
+			}
+			value = marshalString(str)
+			i = end + 1
+		} else {
+			start := i
+			for i < len(s) && !isLogfmtSpace(s[i]) {
+				i++
+			}
+			value = scalarValue(s[start:i])
+		}
+		values[key] = value
+	}
+	if len(values) == 0 {
+		return nil, false
+	}
+	return &Entry{Partials: values, Raw: line}, true
+}
+
+func isLogfmtSpace(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+// unquoteLogfmt unquotes a quoted value, which may use JSON or Go escape sequences.
+func unquoteLogfmt(quoted string) (string, bool) {
+	var s string
+	if err := json.Unmarshal([]byte(quoted), &s); err == nil {
+		return s, true
+	}
+	s, err := strconv.Unquote(quoted)
+	return s, err == nil
+}
+
+// scalarValue encodes a value read from text as JSON. Numbers and booleans are kept as such, anything else is a string.
+func scalarValue(s string) json.RawMessage {
+	if s == "true" || s == "false" {
+		return json.RawMessage(s)
+	}
+	if s != "" && (s[0] == '-' || s[0] >= '0' && s[0] <= '9') && json.Valid([]byte(s)) {
+		return json.RawMessage(s)
+	}
+	return marshalString(s)
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"sort"
 	"strings"
+	"sync"
 )
 
 // DefaultLogfmtPreferredFields is the set of fields that NewLogfmtPrinter orders ahead of other fields.
@@ -29,6 +30,14 @@ type LogfmtPrinter struct {
 	PreferredFields []string
 	// DisableColor disables ANSI color escape sequences.
 	DisableColor    bool
+	// ColorMode is the set of colors supported by the terminal.
+	ColorMode       ColorMode
+	// Theme is used to color the keys by log level. If nil, DefaultTheme is used.
+	Theme           *Theme
+	// Highlighter, if set, is applied to each field value. It is used to mark search matches, see Grep.
+	Highlighter     Transformer
+
+	mu sync.Mutex
 }
 
 // NewLogfmtPrinter allocates and returns a new LogFmtPrinter.
@@ -40,12 +49,20 @@ func NewLogfmtPrinter(w io.Writer) *LogfmtPrinter {
 }
 
 func (p *LogfmtPrinter) Print(input *Entry) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	tag := sourceTag(input, p.DisableColor, p.ColorMode)
 	if input.Partials == nil {
-		fmt.Fprintln(p.Out, string(input.Raw))
+		fmt.Fprintln(p.Out, tag+string(input.Raw))
 		return
 	}
+	fmt.Fprint(p.Out, tag)
 	entry := newLogfmtEntry(input, p.PreferredFields)
-	color := entry.Color()
+	theme := p.Theme
+	if theme == nil {
+		theme = DefaultTheme
+	}
+	style := entry.Style(theme)
 
 	sortedFields := append(entry.preferredFields, entry.sortedFields...)
 	for i, field := range sortedFields {
@@ -54,9 +71,21 @@ func (p *LogfmtPrinter) Print(input *Entry) {
 		}
 		key := field.Key
 		if !p.DisableColor {
-			key = ColorText(color, field.Key)
+			key = style.Render(p.ColorMode, field.Key)
+		}
+		value := toString(field.Value)
+		if p.Highlighter != nil {
+			ctx := Context{
+				Original:     value,
+				Field:        field.Key,
+				Value:        field.Value,
+				Entry:        input,
+				DisableColor: p.DisableColor,
+				ColorMode:    p.ColorMode,
+			}
+			value = p.Highlighter.Transform(&ctx, value)
 		}
-		fmt.Fprintf(p.Out, "%s=%s", key, toString(field.Value))
+		fmt.Fprintf(p.Out, "%s=%s", key, value)
 	}
 	fmt.Fprintln(p.Out)
 }
@@ -100,15 +129,15 @@ func newLogfmtEntry(m *Entry, preferredFields []string) *logfmtEntry {
 	}
 }
 
-func (e *logfmtEntry) Color() Color {
+func (e *logfmtEntry) Style(theme *Theme) Style {
 	level := "info"
 	if levelField, ok := e.partials["level"]; ok {
 		level = toString(levelField)
 	}
-	if color, ok := LevelColors[strings.ToLower(level)]; ok {
-		return color
+	if style, ok := theme.Levels[strings.ToLower(level)]; ok {
+		return style
 	}
-	return Green
+	return theme.Levels["info"]
 }
 
 type field struct {
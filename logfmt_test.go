@@ -0,0 +1,77 @@
+package jl
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogfmtDecoder(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		entry string
+	}{{
+		name:  "basic",
+		line:  `level=info msg="hello world" dur=3ms`,
+		entry: `{"dur":"3ms","level":"info","msg":"hello world"}`,
+	}, {
+		name:  "types",
+		line:  `count=3 ratio=-0.5 ok=true id=007 empty= quoted="42"`,
+		entry: `{"count":3,"empty":"","id":"007","ok":true,"quoted":"42","ratio":-0.5}`,
+	}, {
+		name:  "escapes",
+		line:  "msg=\"say \\\"hi\\\"\\n\" path=/a=b\tcaller=main.go:12",
+		entry: `{"caller":"main.go:12","msg":"say \"hi\"\n","path":"/a=b"}`,
+	}, {
+		name:  "duplicate",
+		line:  `at=info at=error code=H12`,
+		entry: `{"at":"error","code":"H12"}`,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			entry, ok := LogfmtDecoder.Decode([]byte(test.line))
+			require.True(t, ok)
+			assert.Equal(t, test.entry, string(marshalRaw(entry.Partials)))
+			assert.Equal(t, test.line, string(entry.Raw))
+		})
+	}
+
+	for _, line := range []string{
+		"",
+		"plain text",
+		"Starting server with port=8080",
+		`msg="unterminated`,
+		`msg="a"b`,
+		`=value`,
+	} {
+		_, ok := LogfmtDecoder.Decode([]byte(line))
+		assert.False(t, ok, line)
+	}
+}
+
+func TestLogfmtDecoder_MixedStream(t *testing.T) {
+	logs := `{"level":"info","msg":"from json"}
+level=warn msg="from logfmt" caller=main.go:12
+plain text`
+	buf := &bytes.Buffer{}
+	printer := NewCompactPrinter(buf)
+	printer.DisableColor = true
+	parser := NewParser(bytes.NewBufferString(logs), printer)
+	parser.Decoder = Decoders["logfmt"]()
+	require.NoError(t, parser.Consume())
+	assert.Equal(t, `INFO from json
+WARN           main.go:12| from logfmt
+plain text
+`, buf.String())
+}
+
+func TestLogfmtDecoder_GrepRaw(t *testing.T) {
+	entry, ok := LogfmtDecoder.Decode([]byte(`level=info msg="hello world"`))
+	require.True(t, ok)
+	grep, err := NewGrep(`msg="hello`)
+	require.NoError(t, err)
+	assert.True(t, grep.Match(entry))
+}
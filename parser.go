@@ -4,12 +4,44 @@ import (
 	"bufio"
 	"encoding/json"
 	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
 )
 
+// ContinuationField is the field that Parser attaches continuation lines to.
+const ContinuationField = "continuation"
+
+// DefaultContinuationPattern matches the lines of common stack traces: indented lines, Java's "at" and "Caused by"
+// lines, Go's panics and goroutine dumps, Python's tracebacks, and blank lines between them.
+var DefaultContinuationPattern = regexp.MustCompile(`^(\s|at |Caused by: |\.\.\. \d+ (more|common frames omitted)|panic: |goroutine \d+ |Traceback |[\w.$]+(Error|Exception)\b|$)`)
+
+// continuationIdle is how long Parser waits for continuation lines after an entry before printing it.
+const continuationIdle = 100 * time.Millisecond
+
 type Parser struct {
+	// Source is set as the Source of every entry parsed that does not have one.
+	Source string
+	// Decoder decodes the lines of input into entries. Lines it does not accept are printed as is. If nil, JSONDecoder
+	// is used.
+	Decoder Decoder
+	// Continuations attaches the lines that are not decoded to the entry before them, instead of printing them on their
+	// own, so that stack traces printed after an entry stay with it. They are set as the entry's ContinuationField,
+	// and appended to its Raw.
+	Continuations bool
+	// ContinuationPattern, if set, limits Continuations to the lines that match it, like DefaultContinuationPattern.
+	ContinuationPattern *regexp.Regexp
+
 	r       io.Reader
 	scan    *bufio.Scanner
 	printer EntryPrinter
+
+	// mu guards the entry held for continuation lines, which is printed by timer if no more lines follow it soon.
+	mu           sync.Mutex
+	pending      *Entry
+	continuation []string
+	timer        *time.Timer
 }
 
 func NewParser(r io.Reader, h EntryPrinter) *Parser {
@@ -21,25 +53,160 @@ func NewParser(r io.Reader, h EntryPrinter) *Parser {
 }
 
 func (p *Parser) Consume() error {
+	decoder := p.Decoder
+	if decoder == nil {
+		decoder = JSONDecoder
+	}
 	s := p.scan
 	for s.Scan() {
-		raw := s.Bytes()
-		var partials map[string]json.RawMessage
-		_ = json.Unmarshal(raw, &partials)
-		message := &Entry{
-			Partials:    partials,
-			Raw:         raw,
+		// Copy the line, since the scanner reuses its buffer and printers may hold on to entries.
+		line := append([]byte(nil), s.Bytes()...)
+		if entry, ok := decoder.Decode(line); ok {
+			p.handle(entry)
+		} else {
+			p.handleRaw(line)
 		}
-		p.printer.Print(message)
 	}
+	for _, entry := range drain(decoder) {
+		p.handle(entry)
+	}
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+	p.flushPending()
+	Flush(p.printer)
 	return p.scan.Err()
 }
 
+// handle prints a decoded entry, or holds on to it for the continuation lines that may follow it.
+func (p *Parser) handle(entry *Entry) {
+	if entry == nil {
+		return
+	}
+	if !p.Continuations {
+		p.print(entry)
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.printPending()
+	if entry.Partials == nil {
+		p.print(entry)
+		return
+	}
+	p.pending = entry
+	p.resetTimer()
+}
+
+// handleRaw attaches a line that was not decoded to the pending entry if it is a continuation, or prints it as is.
+func (p *Parser) handleRaw(line []byte) {
+	if !p.Continuations {
+		p.print(&Entry{Raw: line})
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.pending != nil && (p.ContinuationPattern == nil || p.ContinuationPattern.Match(line)) {
+		p.continuation = append(p.continuation, string(line))
+		p.resetTimer()
+		return
+	}
+	p.printPending()
+	p.print(&Entry{Raw: line})
+}
+
+func (p *Parser) resetTimer() {
+	if p.timer == nil {
+		p.timer = time.AfterFunc(continuationIdle, p.flushPending)
+	} else {
+		p.timer.Reset(continuationIdle)
+	}
+}
+
+func (p *Parser) flushPending() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.printPending()
+}
+
+// printPending prints the pending entry, with its continuation lines. p.mu must be held.
+func (p *Parser) printPending() {
+	entry := p.pending
+	if entry == nil {
+		return
+	}
+	continuation := p.continuation
+	p.pending, p.continuation = nil, nil
+	lines := strings.Join(continuation, "\n")
+	if strings.TrimSpace(lines) == "" {
+		// Lines that are only whitespace are not worth attaching, but are printed as is so that none are lost.
+		p.print(entry)
+		for _, line := range continuation {
+			p.print(&Entry{Raw: []byte(line)})
+		}
+		return
+	}
+	entry.Partials[ContinuationField] = marshalString(lines)
+	entry.Raw = append(append(entry.Raw, '\n'), lines...)
+	p.print(entry)
+}
+
+func (p *Parser) print(entry *Entry) {
+	if entry == nil {
+		return
+	}
+	if entry.Source == "" {
+		entry.Source = p.Source
+	}
+	p.printer.Print(entry)
+}
+
+// EntryPrinter prints entries. The printers in this package are safe for concurrent use.
 type EntryPrinter interface {
 	Print(*Entry)
 }
 
+// Flusher is implemented by EntryPrinters that hold on to entries before printing them.
+type Flusher interface {
+	// Flush prints all entries held by the printer.
+	Flush()
+}
+
+// Flush flushes the printer if it is a Flusher.
+func Flush(printer EntryPrinter) {
+	if f, ok := printer.(Flusher); ok {
+		f.Flush()
+	}
+}
+
 type Entry struct {
 	Partials    map[string]json.RawMessage
 	Raw         []byte
+	// Source names the stream the entry came from, like "stderr". It is empty for the main input.
+	Source      string
+}
+
+// NewEntry parses a line of log into an Entry. If the line is not a JSON object, Partials is nil.
+func NewEntry(raw []byte) *Entry {
+	var partials map[string]json.RawMessage
+	_ = json.Unmarshal(raw, &partials)
+	return &Entry{
+		Partials: partials,
+		Raw:      raw,
+	}
+}
+
+// sourceStyle is the style of the tag that printers prefix entries from other sources with.
+var sourceStyle = Style{Fg: Magenta}
+
+// sourceTag returns the tag that printers prefix the entry with, or "" if it came from the main input.
+func sourceTag(entry *Entry, disableColor bool, mode ColorMode) string {
+	if entry.Source == "" {
+		return ""
+	}
+	tag := "[" + entry.Source + "]"
+	if !disableColor {
+		tag = sourceStyle.Render(mode, tag)
+	}
+	return tag + " "
 }
@@ -0,0 +1,101 @@
+package jl
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParser_Continuations(t *testing.T) {
+	logs := `{"level":"error","msg":"request failed"}
+java.lang.IllegalStateException: boom
+	at com.example.Foo.bar(Foo.java:10)
+
+{"level":"info","msg":"next"}
+not a stack trace`
+	tests := []struct {
+		name    string
+		pattern bool
+		lines   []string
+	}{{
+		name: "all",
+		lines: []string{
+			"{\"level\":\"error\",\"msg\":\"request failed\"}\njava.lang.IllegalStateException: boom\n\tat com.example.Foo.bar(Foo.java:10)\n",
+			"{\"level\":\"info\",\"msg\":\"next\"}\nnot a stack trace",
+		},
+	}, {
+		name:    "pattern",
+		pattern: true,
+		lines: []string{
+			"{\"level\":\"error\",\"msg\":\"request failed\"}\njava.lang.IllegalStateException: boom\n\tat com.example.Foo.bar(Foo.java:10)\n",
+			`{"level":"info","msg":"next"}`,
+			"not a stack trace",
+		},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			printer := &sourcePrinter{}
+			parser := NewParser(strings.NewReader(logs), printer)
+			parser.Continuations = true
+			if test.pattern {
+				parser.ContinuationPattern = DefaultContinuationPattern
+			}
+			require.NoError(t, parser.Consume())
+			assert.Equal(t, test.lines, printer.lines)
+		})
+	}
+}
+
+func TestParser_ContinuationsPrinted(t *testing.T) {
+	logs := `{"level":"error","msg":"panic","error":"boom"}
+goroutine 1 [running]:
+main.main()`
+	buf := &bytes.Buffer{}
+	printer := NewCompactPrinter(buf)
+	printer.DisableColor = true
+	parser := NewParser(strings.NewReader(logs), printer)
+	parser.Continuations = true
+	require.NoError(t, parser.Consume())
+	assert.Equal(t, "ERRO panic boom\ngoroutine 1 [running]:\nmain.main()\n", buf.String())
+}
+
+func TestParser_ContinuationsBlank(t *testing.T) {
+	printer := &sourcePrinter{}
+	parser := NewParser(strings.NewReader("{\"msg\":\"first\"}\n\n  \n{\"msg\":\"second\"}"), printer)
+	parser.Continuations = true
+	require.NoError(t, parser.Consume())
+	assert.Equal(t, []string{`{"msg":"first"}`, "", "  ", `{"msg":"second"}`}, printer.lines)
+}
+
+func TestParser_ContinuationsAfterLogrusError(t *testing.T) {
+	logs := `{"level":"error","msg":"failed","error":"boom","stack":"main.go:1"}
+Caused by: timeout`
+	buf := &bytes.Buffer{}
+	printer := NewCompactPrinter(buf)
+	printer.DisableColor = true
+	parser := NewParser(strings.NewReader(logs), printer)
+	parser.Continuations = true
+	require.NoError(t, parser.Consume())
+	assert.Equal(t, "ERRO failed\n  boom\n\tmain.go:1\nCaused by: timeout\n", buf.String())
+}
+
+func TestParser_ContinuationsIdle(t *testing.T) {
+	r, w := io.Pipe()
+	printer := &sourcePrinter{}
+	parser := NewParser(r, printer)
+	parser.Continuations = true
+	done := make(chan error)
+	go func() { done <- parser.Consume() }()
+
+	io.WriteString(w, "{\"msg\":\"first\"}\n  continued\n")
+	time.Sleep(3 * continuationIdle)
+	io.WriteString(w, "  too late\n")
+	w.Close()
+	require.NoError(t, <-done)
+	assert.Equal(t, []string{"{\"msg\":\"first\"}\n  continued", "  too late"}, printer.lines)
+}
@@ -0,0 +1,221 @@
+package jl
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type pathSegmentKind int
+
+const (
+	pathKey pathSegmentKind = iota
+	pathIndex
+	pathWildcard
+)
+
+type pathSegment struct {
+	kind  pathSegmentKind
+	key   string
+	index int
+	// dotted is set for keys that were separated from the key before them by a dot, which may be part of the key.
+	dotted bool
+}
+
+// fieldPath is a parsed field path, as accepted by ByNames.
+type fieldPath []pathSegment
+
+// parsePath parses a field path.
+func parsePath(path string) (fieldPath, error) {
+	var segments fieldPath
+	var key strings.Builder
+	inKey, dotted, escaped := false, false, false
+	endKey := func() {
+		if !inKey {
+			return
+		}
+		if key.String() == "*" && !escaped {
+			segments = append(segments, pathSegment{kind: pathWildcard})
+		} else {
+			segments = append(segments, pathSegment{kind: pathKey, key: key.String(), dotted: dotted})
+		}
+		key.Reset()
+		inKey, dotted, escaped = false, false, false
+	}
+	for i := 0; i < len(path); i++ {
+		switch c := path[i]; {
+		case c == '\\' && i+1 < len(path):
+			i++
+			key.WriteByte(path[i])
+			inKey, escaped = true, true
+		case c == '.':
+			endKey()
+			if len(segments) == 0 || i+1 == len(path) || path[i+1] == '.' {
+				return nil, fmt.Errorf("empty key in path %q", path)
+			}
+			dotted = segments[len(segments)-1].kind == pathKey
+		case c == '[':
+			endKey()
+			seg, n, err := parseBracket(path[i:])
+			if err != nil {
+				return nil, fmt.Errorf("%v in path %q", err, path)
+			}
+			segments = append(segments, seg)
+			dotted = false
+			i += n - 1
+		default:
+			key.WriteByte(c)
+			inKey = true
+		}
+	}
+	endKey()
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("empty path")
+	}
+	return segments, nil
+}
+
+// parseBracket parses a segment in brackets at the start of s, and returns it with its length.
+func parseBracket(s string) (pathSegment, int, error) {
+	if len(s) > 1 && (s[1] == '"' || s[1] == '\'') {
+		quote := s[1]
+		var key strings.Builder
+		for i := 2; i < len(s); i++ {
+			switch {
+			case s[i] == '\\' && i+1 < len(s):
+				i++
+				key.WriteByte(s[i])
+			case s[i] == quote:
+				if i+1 >= len(s) || s[i+1] != ']' {
+					return pathSegment{}, 0, fmt.Errorf("expected ] after quoted key")
+				}
+				return pathSegment{kind: pathKey, key: key.String()}, i + 2, nil
+			default:
+				key.WriteByte(s[i])
+			}
+		}
+		return pathSegment{}, 0, fmt.Errorf("unterminated quoted key")
+	}
+	end := strings.IndexByte(s, ']')
+	if end < 0 {
+		return pathSegment{}, 0, fmt.Errorf("unclosed [")
+	}
+	inner := strings.TrimSpace(s[1:end])
+	if inner == "*" {
+		return pathSegment{kind: pathWildcard}, end + 1, nil
+	}
+	index, err := strconv.Atoi(inner)
+	if err != nil {
+		return pathSegment{}, 0, fmt.Errorf("invalid index [%s]", inner)
+	}
+	return pathSegment{kind: pathIndex, index: index}, end + 1, nil
+}
+
+// joinPath appends key to the path, escaping it so that parsePath reads it back as a single key.
+func joinPath(path, key string) string {
+	if key == "" || key == "*" {
+		return path + `["` + key + `"]`
+	}
+	key = strings.NewReplacer(`\`, `\\`, `[`, `\[`, `.`, `\.`).Replace(key)
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// hasWildcard reports whether the path can match more than one value.
+func (p fieldPath) hasWildcard() bool {
+	for _, seg := range p {
+		if seg.kind == pathWildcard {
+			return true
+		}
+	}
+	return false
+}
+
+// find returns the value at the path in the object. If the path has a wildcard, it returns an array of the values it
+// matches.
+func (p fieldPath) find(obj map[string]json.RawMessage) (json.RawMessage, bool) {
+	var matches []json.RawMessage
+	findInObject(obj, p, &matches)
+	if len(matches) == 0 {
+		return nil, false
+	}
+	if p.hasWildcard() {
+		return marshalRaw(matches), true
+	}
+	return matches[0], true
+}
+
+// findInObject appends the values at the path in obj to matches. Keys are looked up as nested objects first, and then
+// as keys containing dots.
+func findInObject(obj map[string]json.RawMessage, path fieldPath, matches *[]json.RawMessage) {
+	seg := path[0]
+	switch seg.kind {
+	case pathWildcard:
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			findInValue(obj[k], path[1:], matches)
+		}
+	case pathKey:
+		key := seg.key
+		for n := 1; n <= len(path); n++ {
+			if n > 1 {
+				if path[n-1].kind != pathKey || !path[n-1].dotted {
+					return
+				}
+				key += "." + path[n-1].key
+			}
+			if v, ok := obj[key]; ok {
+				found := len(*matches)
+				findInValue(v, path[n:], matches)
+				if len(*matches) > found {
+					return
+				}
+			}
+		}
+	}
+}
+
+// findInValue appends the values at the path in v to matches.
+func findInValue(v json.RawMessage, path fieldPath, matches *[]json.RawMessage) {
+	if len(path) == 0 {
+		*matches = append(*matches, v)
+		return
+	}
+	switch v = trimSpace(v); {
+	case len(v) > 0 && v[0] == '{' && path[0].kind != pathIndex:
+		var obj map[string]json.RawMessage
+		if json.Unmarshal(v, &obj) == nil {
+			findInObject(obj, path, matches)
+		}
+	case len(v) > 0 && v[0] == '[' && path[0].kind != pathKey:
+		var arr []json.RawMessage
+		if json.Unmarshal(v, &arr) != nil {
+			return
+		}
+		if path[0].kind == pathWildcard {
+			for _, elem := range arr {
+				findInValue(elem, path[1:], matches)
+			}
+			return
+		}
+		i := path[0].index
+		if i < 0 {
+			i += len(arr)
+		}
+		if i >= 0 && i < len(arr) {
+			findInValue(arr[i], path[1:], matches)
+		}
+	}
+}
+
+func trimSpace(v json.RawMessage) json.RawMessage {
+	return json.RawMessage(strings.TrimSpace(string(v)))
+}
@@ -0,0 +1,69 @@
+package jl
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestByNames_Paths(t *testing.T) {
+	entry := &Entry{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"http": {"request": {"method": "GET"}},
+		"http.status": 200,
+		"url": {"path": "/", "full.text": "http://x/"},
+		"logging.googleapis.com/trace": "abc",
+		"a.b": {"c": 1},
+		"key[0]": "bracket",
+		"star": {"*": "literal"},
+		"errors": [{"message": "first"}, {"message": "last"}],
+		"tags": {"env": "prod", "app": "api"},
+		"spans": [{"name": "db"}, {"id": 2}, {"name": "http"}]
+	}`), &entry.Partials))
+	tests := []struct {
+		path string
+		want interface{}
+	}{
+		{`http.request.method`, json.RawMessage(`"GET"`)},
+		{`http.status`, json.RawMessage(`200`)},
+		{`["http.status"]`, json.RawMessage(`200`)},
+		{`http\.status`, json.RawMessage(`200`)},
+		{`url['full.text']`, json.RawMessage(`"http://x/"`)},
+		{`url.full.text`, json.RawMessage(`"http://x/"`)},
+		{`logging.googleapis.com/trace`, json.RawMessage(`"abc"`)},
+		{`a.b.c`, json.RawMessage(`1`)},
+		{`key\[0]`, json.RawMessage(`"bracket"`)},
+		{`star.\*`, json.RawMessage(`"literal"`)},
+		{`star["*"]`, json.RawMessage(`"literal"`)},
+		{`errors[0].message`, json.RawMessage(`"first"`)},
+		{`errors[-1].message`, json.RawMessage(`"last"`)},
+		{`errors[2]`, nil},
+		{`errors.message`, nil},
+		{`tags.*`, json.RawMessage(`["api","prod"]`)},
+		{`spans[*].name`, json.RawMessage(`["db","http"]`)},
+		{`spans.*.id`, json.RawMessage(`[2]`)},
+		{`tags[*].missing`, nil},
+		{`errors[`, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			assert.Equal(t, test.want, ByNames(test.path)(entry))
+		})
+	}
+}
+
+func TestByNames_InvalidPathIsKey(t *testing.T) {
+	entry := &Entry{Partials: map[string]json.RawMessage{"errors[": json.RawMessage(`1`)}}
+	assert.Equal(t, json.RawMessage(`1`), ByNames("errors[")(entry))
+}
+
+func TestByPath_Invalid(t *testing.T) {
+	for _, path := range []string{"", ".a", "a.", "a..b", "a[", "a[x]", `a["b`, `a["b"`} {
+		t.Run(path, func(t *testing.T) {
+			_, err := ByPath(path)
+			assert.Error(t, err)
+		})
+	}
+}
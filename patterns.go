@@ -0,0 +1,272 @@
+package jl
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PatternWildcard replaces the variable parts of messages in pattern templates.
+const PatternWildcard = "<*>"
+
+// DefaultPatternSimilarity is the default PatternMiner.Similarity.
+const DefaultPatternSimilarity = 0.5
+
+// quotedValue matches single and double quoted values in messages.
+var quotedValue = regexp.MustCompile(`"[^"]*"|'[^']*'`)
+
+// Pattern is a template shared by similar log messages, along with statistics of the entries that matched it.
+type Pattern struct {
+	// Template is the message with the parts that vary between entries replaced with PatternWildcard.
+	Template string
+	// Count is the number of entries matching the pattern.
+	Count int
+	// Levels counts the entries matching the pattern by their normalized level.
+	Levels map[string]int
+	// First and Last are the earliest and latest timestamps of the entries matching the pattern.
+	First, Last time.Time
+	// Example is the message of the first entry that matched the pattern.
+	Example string
+
+	tokens []string
+}
+
+// PatternMiner clusters log messages into patterns, using a simplified version of the Drain algorithm: numbers, IDs
+// and quoted values are masked, and messages with the same number of words and the same first word are merged into one
+// pattern if enough of their words are the same.
+type PatternMiner struct {
+	// Similarity is the fraction of words that two messages must have in common to share a pattern.
+	Similarity float64
+	// MessageFinder finds the message of an entry. Entries without a message are clustered by their raw line.
+	MessageFinder FieldFinder
+
+	mu       sync.Mutex
+	groups   map[string][]*Pattern
+	patterns []*Pattern
+}
+
+// NewPatternMiner allocates and returns a new PatternMiner.
+func NewPatternMiner() *PatternMiner {
+	return &PatternMiner{
+		Similarity:    DefaultPatternSimilarity,
+		MessageFinder: MessageFinder,
+		groups:        make(map[string][]*Pattern),
+	}
+}
+
+// Add clusters the entry, and returns the pattern it was added to.
+func (m *PatternMiner) Add(entry *Entry) *Pattern {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	message := findString(m.MessageFinder, entry)
+	if message == "" {
+		message = string(entry.Raw)
+	}
+	tokens := tokenizeMessage(message)
+	key := strconv.Itoa(len(tokens))
+	if len(tokens) > 0 {
+		key += " " + tokens[0]
+	}
+	var best *Pattern
+	bestSimilarity := 0.0
+	for _, p := range m.groups[key] {
+		if s := similarity(p.tokens, tokens); s > bestSimilarity {
+			best, bestSimilarity = p, s
+		}
+	}
+	if best == nil || bestSimilarity < m.Similarity {
+		best = &Pattern{
+			Levels:  make(map[string]int),
+			Example: message,
+			tokens:  tokens,
+		}
+		m.groups[key] = append(m.groups[key], best)
+		m.patterns = append(m.patterns, best)
+	} else {
+		for i, token := range tokens {
+			if best.tokens[i] != token {
+				best.tokens[i] = PatternWildcard
+			}
+		}
+	}
+	best.Template = strings.Join(best.tokens, " ")
+	best.add(entry)
+	return best
+}
+
+// Patterns returns the patterns found so far, most common first.
+func (m *PatternMiner) Patterns() []*Pattern {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	patterns := append([]*Pattern(nil), m.patterns...)
+	sort.SliceStable(patterns, func(i, j int) bool {
+		return patterns[i].Count > patterns[j].Count
+	})
+	return patterns
+}
+
+func (p *Pattern) add(entry *Entry) {
+	p.Count++
+	if level := EntryLevel(entry); level != "" {
+		p.Levels[level]++
+	}
+	if t, ok := EntryTime(entry); ok {
+		if p.First.IsZero() || t.Before(p.First) {
+			p.First = t
+		}
+		if t.After(p.Last) {
+			p.Last = t
+		}
+	}
+}
+
+// tokenizeMessage splits a message into words, masking quoted values and words containing digits.
+func tokenizeMessage(message string) []string {
+	message = quotedValue.ReplaceAllString(message, PatternWildcard)
+	tokens := strings.Fields(message)
+	for i, token := range tokens {
+		if !strings.ContainsAny(token, "0123456789") {
+			continue
+		}
+		// Keep punctuation at the end of the word, like the comma in "truck 1, it's broken".
+		core := strings.TrimRight(token, ",.;:!?)]}")
+		tokens[i] = PatternWildcard + token[len(core):]
+	}
+	return tokens
+}
+
+// similarity returns the fraction of tokens that are the same in the template and the message, which must have the
+// same number of tokens. Wildcards in the template match any token.
+func similarity(template, tokens []string) float64 {
+	if len(tokens) == 0 {
+		return 1
+	}
+	same := 0
+	for i, token := range tokens {
+		if template[i] == token || template[i] == PatternWildcard {
+			same++
+		}
+	}
+	return float64(same) / float64(len(tokens))
+}
+
+// PatternPrinter clusters entries with a PatternMiner and, when flushed, prints a report of the patterns found: each
+// pattern with its number of entries, levels, first and last timestamps, and an example message.
+type PatternPrinter struct {
+	// Out is the writer where the report is written to.
+	Out io.Writer
+	// Miner clusters the entries.
+	Miner *PatternMiner
+	// Limit is the maximum number of patterns to print. If zero, all patterns are printed.
+	Limit int
+	// DisableColor disables ANSI escape sequences.
+	DisableColor bool
+}
+
+// NewPatternPrinter allocates and returns a new PatternPrinter.
+func NewPatternPrinter(w io.Writer) *PatternPrinter {
+	return &PatternPrinter{
+		Out:   w,
+		Miner: NewPatternMiner(),
+	}
+}
+
+func (p *PatternPrinter) Print(entry *Entry) {
+	p.Miner.Add(entry)
+}
+
+// Flush prints the report.
+func (p *PatternPrinter) Flush() {
+	patterns := p.Miner.Patterns()
+	if p.Limit > 0 && len(patterns) > p.Limit {
+		patterns = patterns[:p.Limit]
+	}
+	if len(patterns) == 0 {
+		return
+	}
+	countWidth := len(strconv.Itoa(patterns[0].Count))
+	indent := strings.Repeat(" ", countWidth+2)
+	for _, pattern := range patterns {
+		template := pattern.Template
+		if !p.DisableColor {
+			template = Style{Bold: true}.Render(ColorModeTrueColor, template)
+		}
+		fmt.Fprintf(p.Out, "%*d  %s\n", countWidth, pattern.Count, template)
+		var details []string
+		for _, level := range sortLevels(pattern.Levels) {
+			details = append(details, fmt.Sprintf("%s %d", strings.ToUpper(level), pattern.Levels[level]))
+		}
+		if !pattern.First.IsZero() {
+			details = append(details, pattern.First.Format(time.RFC3339)+" – "+pattern.Last.Format(time.RFC3339))
+		}
+		if len(details) > 0 {
+			fmt.Fprintf(p.Out, "%s%s\n", indent, strings.Join(details, " · "))
+		}
+		fmt.Fprintf(p.Out, "%se.g. %s\n", indent, pattern.Example)
+	}
+}
+
+// CollapsePrinter folds consecutive entries that share a pattern. The first entry is printed by Printer, and the rest
+// are summarized in a single line with their count once an entry with a different pattern arrives.
+type CollapsePrinter struct {
+	// Out is the writer that summaries are written to. It should be the same writer that Printer writes to.
+	Out io.Writer
+	// Printer prints the first entry of each run of entries.
+	Printer EntryPrinter
+	// Miner clusters the entries.
+	Miner *PatternMiner
+	// DisableColor disables ANSI escape sequences.
+	DisableColor bool
+
+	mu      sync.Mutex
+	current *Pattern
+	folded  int
+}
+
+// NewCollapsePrinter allocates and returns a new CollapsePrinter.
+func NewCollapsePrinter(w io.Writer, printer EntryPrinter) *CollapsePrinter {
+	return &CollapsePrinter{
+		Out:     w,
+		Printer: printer,
+		Miner:   NewPatternMiner(),
+	}
+}
+
+func (p *CollapsePrinter) Print(entry *Entry) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pattern := p.Miner.Add(entry)
+	if pattern == p.current {
+		p.folded++
+		return
+	}
+	p.printFolded()
+	p.current = pattern
+	p.Printer.Print(entry)
+}
+
+// Flush prints the summary of the current run of entries.
+func (p *CollapsePrinter) Flush() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.printFolded()
+	p.current = nil
+	Flush(p.Printer)
+}
+
+func (p *CollapsePrinter) printFolded() {
+	if p.folded == 0 {
+		return
+	}
+	summary := fmt.Sprintf("  … ×%d more: %s", p.folded, p.current.Template)
+	if !p.DisableColor {
+		summary = Style{Dim: true}.Render(ColorModeTrueColor, summary)
+	}
+	fmt.Fprintln(p.Out, summary)
+	p.folded = 0
+}
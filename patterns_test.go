@@ -0,0 +1,66 @@
+package jl
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const patternLogs = `{"timestamp":"2019-01-01T15:24:45Z","level":"info","message":"Fixing truck 1, it's got a broken axle"}
+{"timestamp":"2019-01-01T15:25:45Z","level":"info","message":"Fixing truck 2, it's got a broken axle"}
+{"timestamp":"2019-01-01T15:26:45Z","level":"warn","message":"Fixing truck 3, it's got a broken axle"}
+{"timestamp":"2019-01-01T15:27:45Z","level":"info","message":"Order 'abc' shipped to warehouse"}
+{"timestamp":"2019-01-01T15:28:45Z","level":"info","message":"Order 'xyz' shipped to store"}
+not json 1
+not json 2`
+
+func parseLines(t *testing.T, logs string) []*Entry {
+	var entries []*Entry
+	for _, line := range strings.Split(logs, "\n") {
+		entry := &Entry{Raw: []byte(line)}
+		if json.Unmarshal(entry.Raw, &entry.Partials) != nil {
+			entry.Partials = nil
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestPatternMiner_Add(t *testing.T) {
+	miner := NewPatternMiner()
+	for _, entry := range parseLines(t, patternLogs) {
+		miner.Add(entry)
+	}
+	patterns := miner.Patterns()
+	require.Len(t, patterns, 3)
+	assert.Equal(t, "Fixing truck <*>, it's got a broken axle", patterns[0].Template)
+	assert.Equal(t, 3, patterns[0].Count)
+	assert.Equal(t, map[string]int{"info": 2, "warn": 1}, patterns[0].Levels)
+	assert.Equal(t, "2019-01-01T15:24:45Z", patterns[0].First.UTC().Format("2006-01-02T15:04:05Z"))
+	assert.Equal(t, "2019-01-01T15:26:45Z", patterns[0].Last.UTC().Format("2006-01-02T15:04:05Z"))
+	assert.Equal(t, "Fixing truck 1, it's got a broken axle", patterns[0].Example)
+	assert.Equal(t, "Order <*> shipped to <*>", patterns[1].Template)
+	assert.Equal(t, "not json <*>", patterns[2].Template)
+}
+
+func TestCollapsePrinter_Print(t *testing.T) {
+	buf := &bytes.Buffer{}
+	printer := NewCollapsePrinter(buf, NewLogfmtPrinter(buf))
+	printer.DisableColor = true
+	printer.Printer.(*LogfmtPrinter).DisableColor = true
+	for _, entry := range parseLines(t, patternLogs) {
+		printer.Print(entry)
+	}
+	printer.Flush()
+	assert.Equal(t, `timestamp=2019-01-01T15:24:45Z level=info message=Fixing truck 1, it's got a broken axle
+  … ×2 more: Fixing truck <*>, it's got a broken axle
+timestamp=2019-01-01T15:27:45Z level=info message=Order 'abc' shipped to warehouse
+  … ×1 more: Order <*> shipped to <*>
+not json 1
+  … ×1 more: not json <*>
+`, buf.String())
+}
@@ -0,0 +1,99 @@
+package jl
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// printConcurrently prints copies of the entries with the printer from several goroutines at once, and then flushes
+// it. Run with -race to check that the printer is safe for concurrent use.
+func printConcurrently(t *testing.T, printer EntryPrinter, logs string) {
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, entry := range parseLines(t, logs) {
+				printer.Print(entry)
+			}
+		}()
+	}
+	wg.Wait()
+	Flush(printer)
+}
+
+func TestPrinters_Concurrent(t *testing.T) {
+	var logs []string
+	for i := 0; i < 20; i++ {
+		logs = append(logs, fmt.Sprintf(
+			`{"timestamp":"2019-01-01T00:00:%02dZ","level":"info","thread":"worker-%d","logger":"app","message":"request %d handled"}`,
+			i, i%5, i))
+	}
+	grep, err := NewGrep("request")
+	require.NoError(t, err)
+	compact := func(w io.Writer) EntryPrinter { return NewCompactPrinter(w) }
+	dir, err := ioutil.TempDir("", "jl-concurrent")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	tests := []struct {
+		name       string
+		newPrinter func(w io.Writer) EntryPrinter
+	}{
+		{"compact", compact},
+		{"logfmt", func(w io.Writer) EntryPrinter { return NewLogfmtPrinter(w) }},
+		{"grep", func(w io.Writer) EntryPrinter { return NewGrepPrinter(w, NewCompactPrinter(w), grep) }},
+		{"dedupe", func(w io.Writer) EntryPrinter { return NewDedupePrinter(w, compact) }},
+		{"trace", func(w io.Writer) EntryPrinter { return NewTracePrinter(w, compact) }},
+		{"collapse", func(w io.Writer) EntryPrinter { return NewCollapsePrinter(w, NewCompactPrinter(w)) }},
+		{"patterns", func(w io.Writer) EntryPrinter { return NewPatternPrinter(w) }},
+		{"fields", func(w io.Writer) EntryPrinter { return NewFieldsPrinter(w) }},
+		{"histogram", func(w io.Writer) EntryPrinter { return NewHistogramPrinter(w) }},
+		{"redact", func(w io.Writer) EntryPrinter { return NewRedactPrinter(NewCompactPrinter(w), NewRedactor()) }},
+		{"split", func(w io.Writer) EntryPrinter {
+			sp := NewSplitPrinter(dir, ByNames("thread"))
+			sp.MaxOpenFiles = 2
+			// Copy what is written to the files to w, so that the test can check that something was printed.
+			sp.NewPrinter = func(f io.Writer) EntryPrinter { return NewCompactPrinter(io.MultiWriter(f, w)) }
+			return sp
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			printConcurrently(t, test.newPrinter(buf), strings.Join(logs, "\n"))
+			assert.NotEmpty(t, buf.String())
+		})
+	}
+}
+
+func TestCompactPrinter_PrintConcurrent(t *testing.T) {
+	log := `{"level":"info","thread":"main","message":"hello"}`
+	buf := &bytes.Buffer{}
+	printConcurrently(t, NewCompactPrinter(buf), log)
+	once := &bytes.Buffer{}
+	NewCompactPrinter(once).Print(parseLines(t, log)[0])
+	// Entries are printed whole, without the output of different goroutines mixed together.
+	assert.Equal(t, strings.Repeat(once.String(), 8), buf.String())
+}
+
+func TestNewCompactPrinter_OwnColorizers(t *testing.T) {
+	first := NewCompactPrinter(&bytes.Buffer{})
+	second := NewCompactPrinter(&bytes.Buffer{})
+	for i := range first.FieldFormats {
+		for j := range first.FieldFormats[i].Transformers {
+			if _, ok := first.FieldFormats[i].Transformers[j].(*hashColorizer); ok {
+				assert.False(t, first.FieldFormats[i].Transformers[j] == second.FieldFormats[i].Transformers[j],
+					"printers share the colorizer of %s", first.FieldFormats[i].Name)
+			}
+		}
+	}
+}
@@ -0,0 +1,318 @@
+package jl
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"path"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// RedactMode controls what redacted values are replaced with.
+type RedactMode int
+
+const (
+	// RedactMask replaces values with RedactedText.
+	RedactMask RedactMode = iota
+	// RedactPartial masks values with "*", keeping the last 4 characters, or the domain of email addresses.
+	RedactPartial
+	// RedactHash replaces values with a short hash, so that entries with the same value can still be correlated.
+	RedactHash
+)
+
+// RedactedText replaces values redacted with RedactMask.
+const RedactedText = "[REDACTED]"
+
+// Detector finds sensitive values like credentials and personal information in text.
+type Detector struct {
+	// Name of the kind of value detected, included in masked values.
+	Name string
+	// Pattern matches candidate values.
+	Pattern *regexp.Regexp
+	// Validate, if set, is called with each match of Pattern to weed out false positives.
+	Validate func(match string) bool
+}
+
+var (
+	// JWTDetector detects JSON Web Tokens.
+	JWTDetector = Detector{
+		Name:    "jwt",
+		Pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`),
+	}
+	// AWSKeyDetector detects AWS access key IDs.
+	AWSKeyDetector = Detector{
+		Name:    "aws-key",
+		Pattern: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),
+	}
+	// EmailDetector detects email addresses.
+	EmailDetector = Detector{
+		Name:    "email",
+		Pattern: regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`),
+	}
+	// CreditCardDetector detects credit card numbers, optionally grouped with spaces or dashes, that pass the Luhn
+	// check.
+	CreditCardDetector = Detector{
+		Name:     "card",
+		Pattern:  regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`),
+		Validate: luhnValid,
+	}
+	// IPDetector detects IPv4 and IPv6 addresses.
+	IPDetector = Detector{
+		Name:     "ip",
+		Pattern:  regexp.MustCompile(`\b(?:\d{1,3}(?:\.\d{1,3}){3}|[0-9A-Fa-f]{0,4}(?::[0-9A-Fa-f]{0,4}){2,7})\b`),
+		Validate: ipValid,
+	}
+)
+
+// DefaultRedactKeys are the field name patterns redacted by NewRedactor.
+var DefaultRedactKeys = []string{
+	"password",
+	"passwd",
+	"secret",
+	"authorization",
+	"cookie",
+	"set-cookie",
+	"*token*",
+	"*secret*",
+	"*password*",
+	"*apikey*",
+	"*api_key*",
+	"*api-key*",
+	"*credential*",
+}
+
+// DefaultDetectors are the detectors used by NewRedactor.
+var DefaultDetectors = []Detector{
+	JWTDetector,
+	AWSKeyDetector,
+	EmailDetector,
+	CreditCardDetector,
+	IPDetector,
+}
+
+// Redactor masks secrets and personal information in log entries.
+type Redactor struct {
+	// Keys are patterns for the names of fields whose values are redacted entirely, at any depth in the entry. They
+	// are matched case-insensitively with path.Match, so "*token*" matches "accessToken".
+	Keys []string
+	// Detectors find sensitive values inside of other fields, and in lines that are not JSON.
+	Detectors []Detector
+	// Mode controls what redacted values are replaced with.
+	Mode RedactMode
+	// Salt is mixed into the hashes of RedactHash, so they cannot be reversed by hashing guesses without knowing it.
+	Salt string
+}
+
+// NewRedactor allocates and returns a new Redactor with the default keys and detectors.
+func NewRedactor() *Redactor {
+	return &Redactor{
+		Keys:      DefaultRedactKeys,
+		Detectors: DefaultDetectors,
+	}
+}
+
+// Redact masks the sensitive values in the entry. If anything is redacted from a JSON entry, Raw is replaced with the
+// redacted entry re-encoded as JSON.
+func (r *Redactor) Redact(entry *Entry) {
+	if entry.Partials == nil {
+		entry.Raw = []byte(r.redactString(string(entry.Raw)))
+		return
+	}
+	if r.redactObject(entry.Partials) {
+		if raw, err := json.Marshal(entry.Partials); err == nil {
+			entry.Raw = raw
+		}
+	}
+}
+
+// redactObject redacts the values of the object in place, returning whether anything was redacted.
+func (r *Redactor) redactObject(obj map[string]json.RawMessage) bool {
+	changed := false
+	for k, v := range obj {
+		if r.matchKey(k) {
+			if s := toString(v); s != "" && s != "null" {
+				obj[k] = marshalString(r.replace(s, ""))
+				changed = true
+			}
+			continue
+		}
+		if redacted, ok := r.redactValue(v); ok {
+			obj[k] = redacted
+			changed = true
+		}
+	}
+	return changed
+}
+
+// redactValue returns the redacted JSON value, and whether anything was redacted.
+func (r *Redactor) redactValue(v json.RawMessage) (json.RawMessage, bool) {
+	trimmed := strings.TrimSpace(string(v))
+	if trimmed == "" {
+		return v, false
+	}
+	switch c := trimmed[0]; {
+	case c == '{':
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(v, &obj); err != nil || !r.redactObject(obj) {
+			return v, false
+		}
+		return marshalRaw(obj), true
+	case c == '[':
+		var arr []json.RawMessage
+		if err := json.Unmarshal(v, &arr); err != nil {
+			return v, false
+		}
+		changed := false
+		for i, elem := range arr {
+			if redacted, ok := r.redactValue(elem); ok {
+				arr[i] = redacted
+				changed = true
+			}
+		}
+		if !changed {
+			return v, false
+		}
+		return marshalRaw(arr), true
+	case c == '"':
+		// Numbers are left alone, since timestamps in milliseconds or nanoseconds are easily mistaken for card numbers.
+		s := toString(v)
+		if redacted := r.redactString(s); redacted != s {
+			return marshalString(redacted), true
+		}
+	}
+	return v, false
+}
+
+// redactString replaces the values found by the detectors in s.
+func (r *Redactor) redactString(s string) string {
+	for _, d := range r.Detectors {
+		d := d
+		s = d.Pattern.ReplaceAllStringFunc(s, func(match string) string {
+			if d.Validate != nil && !d.Validate(match) {
+				return match
+			}
+			return r.replace(match, d.Name)
+		})
+	}
+	return s
+}
+
+func (r *Redactor) matchKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, pattern := range r.Keys {
+		if ok, _ := path.Match(strings.ToLower(pattern), key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// replace returns the replacement for a sensitive value found by the named detector, or in a redacted field if kind is
+// empty.
+func (r *Redactor) replace(value, kind string) string {
+	switch r.Mode {
+	case RedactPartial:
+		if kind == EmailDetector.Name {
+			if at := strings.LastIndexByte(value, '@'); at > 0 {
+				return value[:1] + strings.Repeat("*", utf8.RuneCountInString(value[1:at])) + value[at:]
+			}
+		}
+		n := utf8.RuneCountInString(value)
+		if n <= 8 {
+			return strings.Repeat("*", n)
+		}
+		runes := []rune(value)
+		return strings.Repeat("*", n-4) + string(runes[n-4:])
+	case RedactHash:
+		sum := sha256.Sum256([]byte(r.Salt + value))
+		return "[" + hashPrefix(kind) + hex.EncodeToString(sum[:6]) + "]"
+	default:
+		if kind == "" {
+			return RedactedText
+		}
+		return "[REDACTED:" + kind + "]"
+	}
+}
+
+func hashPrefix(kind string) string {
+	if kind == "" {
+		return "#"
+	}
+	return kind + "#"
+}
+
+func marshalString(s string) json.RawMessage {
+	b, _ := json.Marshal(s)
+	return b
+}
+
+func marshalRaw(v interface{}) json.RawMessage {
+	b, _ := json.Marshal(v)
+	return b
+}
+
+// luhnValid reports whether the digits in s pass the Luhn checksum used by credit card numbers.
+func luhnValid(s string) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && n <= 19 && sum%10 == 0
+}
+
+// ipValid reports whether s is an IP address. IPv6 addresses need at least three groups of digits, so that things like
+// times and C++ scopes are not mistaken for them.
+func ipValid(s string) bool {
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return false
+	}
+	if strings.Contains(s, ".") {
+		return true
+	}
+	groups := 0
+	for _, g := range strings.Split(s, ":") {
+		if g != "" {
+			groups++
+		}
+	}
+	return groups >= 3
+}
+
+// RedactPrinter redacts entries with Redactor before passing them on to Printer.
+type RedactPrinter struct {
+	Printer  EntryPrinter
+	Redactor *Redactor
+}
+
+// NewRedactPrinter allocates and returns a new RedactPrinter.
+func NewRedactPrinter(printer EntryPrinter, redactor *Redactor) *RedactPrinter {
+	return &RedactPrinter{
+		Printer:  printer,
+		Redactor: redactor,
+	}
+}
+
+func (p *RedactPrinter) Print(entry *Entry) {
+	p.Redactor.Redact(entry)
+	p.Printer.Print(entry)
+}
+
+func (p *RedactPrinter) Flush() {
+	Flush(p.Printer)
+}
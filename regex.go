@@ -0,0 +1,142 @@
+package jl
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// GrokPatterns are the patterns that grok patterns compiled by CompileGrok can refer to by name, as %{NAME}. More
+// may be added.
+var GrokPatterns = map[string]string{
+	"INT":               `[+-]?\d+`,
+	"NUMBER":            `[+-]?(?:\d+(?:\.\d*)?|\.\d+)`,
+	"WORD":              `\w+`,
+	"NOTSPACE":          `\S+`,
+	"SPACE":             `\s*`,
+	"DATA":              `.*?`,
+	"GREEDYDATA":        `.*`,
+	"QUOTEDSTRING":      `"(?:[^"\\]|\\.)*"`,
+	"IPV4":              `(?:\d{1,3}\.){3}\d{1,3}`,
+	"IPV6":              `[0-9A-Fa-f:]*:[0-9A-Fa-f:.]*`,
+	"IP":                `%{IPV6}|%{IPV4}`,
+	"HOSTNAME":          `[0-9A-Za-z][0-9A-Za-z-]*(?:\.[0-9A-Za-z][0-9A-Za-z-]*)*\.?`,
+	"IPORHOST":          `%{IP}|%{HOSTNAME}`,
+	"USER":              `[\w.@-]+`,
+	"PATH":              `/[^\s?#]*`,
+	"URIPATHPARAM":      `\S+`,
+	"HTTPDATE":          `\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}`,
+	"TIMESTAMP_ISO8601": `\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}(?::\d{2}(?:[.,]\d+)?)?(?:Z|[+-]\d{2}:?\d{2})?`,
+	"LOGLEVEL":          `(?i:trace|debug|info|notice|warn(?:ing)?|error|err|severe|fatal|critical|crit|alert|emerg(?:ency)?|panic)`,
+	"JAVACLASS":         `(?:[a-zA-Z$_][a-zA-Z$_0-9]*\.)*[a-zA-Z$_][a-zA-Z$_0-9]*`,
+	// REQUEST is the request line of an access log, which is captured as the message.
+	"REQUEST": `(?P<message>%{WORD:method} %{NOTSPACE:path}(?: HTTP/%{NUMBER:http_version})?|[^"]*)`,
+}
+
+// TextFormats are grok patterns for common plain-text log formats, by name.
+var TextFormats = map[string]string{
+	// nginx's default combined format, which is also Apache's combined format.
+	"nginx": `^%{IPORHOST:remote_addr} - %{NOTSPACE:remote_user} \[%{HTTPDATE:timestamp}\] "%{REQUEST}" %{INT:status} %{NOTSPACE:bytes} "%{DATA:referrer}" "%{DATA:user_agent}"`,
+	// Apache's common format, optionally followed by the referrer and user agent of the combined format.
+	"apache": `^%{IPORHOST:remote_addr} %{NOTSPACE:ident} %{NOTSPACE:remote_user} \[%{HTTPDATE:timestamp}\] "%{REQUEST}" %{INT:status} %{NOTSPACE:bytes}(?: "%{DATA:referrer}" "%{DATA:user_agent}")?`,
+	// PostgreSQL with a log_line_prefix of '%m [%p] ', optionally followed by '%q%u@%d '.
+	"postgres": `^%{TIMESTAMP_ISO8601:timestamp}(?: %{WORD:timezone})? \[%{INT:pid}\](?: %{DATA:user}@%{DATA:database})? %{WORD:level}:\s+%{GREEDYDATA:message}`,
+	// log4j and logback with a pattern like '%d [%t] %-5p %c - %m%n'.
+	"log4j": `^%{TIMESTAMP_ISO8601:timestamp} \[%{DATA:thread}\] %{LOGLEVEL:level}\s+%{JAVACLASS:logger} - %{GREEDYDATA:message}`,
+}
+
+// maxGrokDepth is the maximum depth of patterns referring to other patterns, to stop cycles.
+const maxGrokDepth = 10
+
+// grokRef matches references to patterns, like %{NAME} or %{NAME:field}.
+var grokRef = regexp.MustCompile(`%\{(\w+)(?::(\w+))?\}`)
+
+// CompileGrok compiles a grok pattern: a regular expression that may refer to GrokPatterns by name. %{NAME} matches
+// the pattern, and %{NAME:field} also captures what it matched as the field. Plain regular expressions with named
+// capture groups, like (?P<field>\d+), are grok patterns too.
+func CompileGrok(pattern string) (*regexp.Regexp, error) {
+	expanded, err := expandGrok(pattern, 0)
+	if err != nil {
+		return nil, err
+	}
+	return regexp.Compile(expanded)
+}
+
+func expandGrok(pattern string, depth int) (string, error) {
+	if depth > maxGrokDepth {
+		return "", fmt.Errorf("grok patterns nested too deeply in %s", pattern)
+	}
+	var err error
+	expanded := grokRef.ReplaceAllStringFunc(pattern, func(ref string) string {
+		m := grokRef.FindStringSubmatch(ref)
+		sub, ok := GrokPatterns[m[1]]
+		if !ok {
+			err = fmt.Errorf("unknown grok pattern %s", m[1])
+			return ref
+		}
+		sub, subErr := expandGrok(sub, depth+1)
+		if subErr != nil {
+			err = subErr
+			return ref
+		}
+		if m[2] != "" {
+			return "(?P<" + m[2] + ">" + sub + ")"
+		}
+		return "(?:" + sub + ")"
+	})
+	return expanded, err
+}
+
+// RegexDecoder decodes plain-text lines with regular expressions, capturing the named groups of the first one that
+// matches as the fields of the entry. Groups that match nothing are left out. Values are decoded as strings, except for
+// numbers and booleans. The entry's Raw is the original line.
+type RegexDecoder struct {
+	// Patterns are the regular expressions matched against each line, in order.
+	Patterns []*regexp.Regexp
+}
+
+// NewRegexDecoder allocates and returns a new RegexDecoder. Each pattern is either the name of one of TextFormats, or
+// a grok pattern to be compiled with CompileGrok.
+func NewRegexDecoder(patterns ...string) (*RegexDecoder, error) {
+	d := &RegexDecoder{}
+	for _, pattern := range patterns {
+		if format, ok := TextFormats[pattern]; ok {
+			pattern = format
+		}
+		re, err := CompileGrok(pattern)
+		if err != nil {
+			return nil, err
+		}
+		d.Patterns = append(d.Patterns, re)
+	}
+	return d, nil
+}
+
+func (d *RegexDecoder) Decode(line []byte) (*Entry, bool) {
+	s := string(line)
+	for _, re := range d.Patterns {
+		m := re.FindStringSubmatchIndex(s)
+		if m == nil {
+			continue
+		}
+		values := make(map[string]json.RawMessage)
+		for i, name := range re.SubexpNames() {
+			if name == "" || m[2*i] < 0 || m[2*i] == m[2*i+1] {
+				continue
+			}
+			values[name] = scalarValue(strings.TrimSpace(s[m[2*i]:m[2*i+1]]))
+		}
+		return &Entry{Partials: values, Raw: line}, true
+	}
+	return nil, false
+}
+
+// textFormatDecoder returns a RegexDecoder for one of TextFormats.
+func textFormatDecoder(name string) Decoder {
+	d, err := NewRegexDecoder(name)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
@@ -0,0 +1,84 @@
+package jl
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegexDecoder_TextFormats(t *testing.T) {
+	tests := []struct {
+		format string
+		line   string
+		entry  string
+	}{{
+		format: "nginx",
+		line:   `10.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "GET /index.html?q=1 HTTP/1.1" 404 153 "-" "curl/7.64.1"`,
+		entry:  `{"remote_addr":"10.0.0.1","remote_user":"-","timestamp":"10/Oct/2000:13:55:36 -0700","message":"GET /index.html?q=1 HTTP/1.1","method":"GET","path":"/index.html?q=1","http_version":1.1,"status":404,"bytes":153,"referrer":"-","user_agent":"curl/7.64.1"}`,
+	}, {
+		format: "apache",
+		line:   `127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326`,
+		entry:  `{"remote_addr":"127.0.0.1","ident":"-","remote_user":"frank","timestamp":"10/Oct/2000:13:55:36 -0700","message":"GET /apache_pb.gif HTTP/1.0","method":"GET","path":"/apache_pb.gif","http_version":1.0,"status":200,"bytes":2326}`,
+	}, {
+		format: "apache",
+		line:   `::1 - - [10/Oct/2000:13:55:36 -0700] "-" 408 - "-" "-"`,
+		entry:  `{"remote_addr":"::1","ident":"-","remote_user":"-","timestamp":"10/Oct/2000:13:55:36 -0700","message":"-","status":408,"bytes":"-","referrer":"-","user_agent":"-"}`,
+	}, {
+		format: "postgres",
+		line:   `2019-01-01 00:00:00.123 UTC [1234] app@orders ERROR:  relation "users" does not exist`,
+		entry:  `{"timestamp":"2019-01-01 00:00:00.123","timezone":"UTC","pid":1234,"user":"app","database":"orders","level":"ERROR","message":"relation \"users\" does not exist"}`,
+	}, {
+		format: "log4j",
+		line:   `2019-01-01 00:00:00,123 [http-nio-8080-exec-1] INFO  com.example.OrderService - Order 42 placed`,
+		entry:  `{"timestamp":"2019-01-01 00:00:00,123","thread":"http-nio-8080-exec-1","level":"INFO","logger":"com.example.OrderService","message":"Order 42 placed"}`,
+	}}
+	for _, test := range tests {
+		t.Run(test.format, func(t *testing.T) {
+			entry, ok := Decoders[test.format]().Decode([]byte(test.line))
+			require.True(t, ok)
+			assert.JSONEq(t, test.entry, string(marshalRaw(entry.Partials)))
+			assert.Equal(t, test.line, string(entry.Raw))
+		})
+	}
+}
+
+func TestRegexDecoder(t *testing.T) {
+	d, err := NewRegexDecoder(`^(?P<level>[A-Z]+): (?P<message>.*)$`, `%{WORD:message} %{INT:status}`)
+	require.NoError(t, err)
+
+	entry, ok := d.Decode([]byte("WARN: low disk"))
+	require.True(t, ok)
+	assert.JSONEq(t, `{"level":"WARN","message":"low disk"}`, string(marshalRaw(entry.Partials)))
+	assert.Equal(t, "WARN: low disk", string(entry.Raw))
+	entry, ok = d.Decode([]byte("ok 200"))
+	require.True(t, ok)
+	assert.JSONEq(t, `{"message":"ok","status":200}`, string(marshalRaw(entry.Partials)))
+	_, ok = d.Decode([]byte("no match"))
+	assert.False(t, ok)
+}
+
+func TestDecoders_Regex(t *testing.T) {
+	assert.Contains(t, DecoderNames(), "regex")
+	d := Decoders["regex"]()
+	_, ok := d.Decode([]byte("WARN: low disk"))
+	assert.False(t, ok)
+
+	re, err := CompileGrok(`^%{WORD:level}: %{GREEDYDATA:message}`)
+	require.NoError(t, err)
+	d.(*RegexDecoder).Patterns = []*regexp.Regexp{re}
+	entry, ok := d.Decode([]byte("WARN: low disk"))
+	require.True(t, ok)
+	assert.JSONEq(t, `{"level":"WARN","message":"low disk"}`, string(marshalRaw(entry.Partials)))
+}
+
+func TestCompileGrok_Errors(t *testing.T) {
+	_, err := CompileGrok(`%{NOPE:x}`)
+	assert.EqualError(t, err, "unknown grok pattern NOPE")
+
+	GrokPatterns["LOOP"] = `a%{LOOP}`
+	defer delete(GrokPatterns, "LOOP")
+	_, err = CompileGrok(`%{LOOP}`)
+	assert.Error(t, err)
+}
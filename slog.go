@@ -0,0 +1,225 @@
+//go:build go1.21
+// +build go1.21
+
+package jl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/mattn/go-isatty"
+)
+
+// SlogHandler is a slog.Handler that prints records with an EntryPrinter, so that programs get jl's formatting
+// without piping their output through jl. Each record is converted to an entry with the timestamp, level and message,
+// followed by its attributes, with groups as nested objects. Attributes at the top level that are named like the
+// timestamp, level or message fields, like "msg" or "time", are prefixed with "attr." so that they do not replace the
+// record's own fields.
+type SlogHandler struct {
+	// Printer prints the records. Its settings, like the FieldFormats of a CompactPrinter, may be changed before the
+	// handler is first used. It must be safe for concurrent use, as the printers in this package are.
+	Printer EntryPrinter
+	// Level is the minimum level of the records printed. If nil, records at slog.LevelInfo and above are printed.
+	Level slog.Leveler
+
+	// goas are the groups and attributes added with WithGroup and WithAttrs, in order.
+	goas []groupOrAttrs
+}
+
+type groupOrAttrs struct {
+	group string
+	attrs []slog.Attr
+}
+
+// NewSlogHandler allocates and returns a new SlogHandler that prints records to w with a CompactPrinter. Color is
+// enabled if w is a terminal that supports it.
+func NewSlogHandler(w io.Writer) *SlogHandler {
+	printer := NewCompactPrinter(w)
+	printer.ColorMode = DetectColorMode()
+	printer.DisableColor = printer.ColorMode == ColorModeNone || !isTerminal(w)
+	return &SlogHandler{
+		Printer: printer,
+	}
+}
+
+// isTerminal reports whether w is a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && (isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd()))
+}
+
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	min := slog.LevelInfo
+	if h.Level != nil {
+		min = h.Level.Level()
+	}
+	return level >= min
+}
+
+func (h *SlogHandler) Handle(_ context.Context, r slog.Record) error {
+	root := &slogObject{top: true}
+	if !r.Time.IsZero() {
+		root.add("timestamp", marshalString(r.Time.Format(time.RFC3339Nano)))
+	}
+	root.add("level", marshalString(r.Level.String()))
+	root.add("message", marshalString(r.Message))
+	obj := root
+	for _, goa := range h.goas {
+		if goa.group != "" {
+			group := &slogObject{}
+			obj.add(goa.group, group)
+			obj = group
+			continue
+		}
+		for _, a := range goa.attrs {
+			obj.addAttr(a)
+		}
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		obj.addAttr(a)
+		return true
+	})
+
+	var buf bytes.Buffer
+	root.encode(&buf)
+	entry := NewEntry(buf.Bytes())
+	h.Printer.Print(entry)
+	return nil
+}
+
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.with(groupOrAttrs{attrs: attrs})
+}
+
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(groupOrAttrs{group: name})
+}
+
+func (h *SlogHandler) with(goa groupOrAttrs) *SlogHandler {
+	h2 := *h
+	h2.goas = append(append([]groupOrAttrs(nil), h.goas...), goa)
+	return &h2
+}
+
+// slogRecordKeys are the top-level keys looked up by TimestampFinder, LevelFinder and MessageFinder.
+var slogRecordKeys = map[string]bool{
+	"timestamp": true, "time": true, "ts": true, "@timestamp": true,
+	"level": true, "severity": true, "logLevel": true,
+	"message": true, "msg": true, "textPayload": true,
+}
+
+// slogObject is a JSON object built from slog attributes, which keeps the order of its keys.
+type slogObject struct {
+	// top is set on the object of the entry itself, where attributes must not collide with the record's fields.
+	top  bool
+	keys []string
+	// values holds json.RawMessages and *slogObjects.
+	values []interface{}
+}
+
+func (o *slogObject) add(key string, value interface{}) {
+	o.keys = append(o.keys, key)
+	o.values = append(o.values, value)
+}
+
+// addAttr adds the attribute to the object, following the rules of slog.Handler: empty attributes are ignored, and
+// the attributes of groups with empty keys are inlined.
+func (o *slogObject) addAttr(a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	key := a.Key
+	if o.top && slogRecordKeys[key] {
+		key = "attr." + key
+	}
+	if a.Value.Kind() != slog.KindGroup {
+		o.add(key, slogValue(a.Value))
+		return
+	}
+	attrs := a.Value.Group()
+	if len(attrs) == 0 {
+		return
+	}
+	group := o
+	if a.Key != "" {
+		group = &slogObject{}
+		o.add(key, group)
+	}
+	for _, ga := range attrs {
+		group.addAttr(ga)
+	}
+}
+
+// empty reports whether the object has no values, other than empty objects.
+func (o *slogObject) empty() bool {
+	for _, v := range o.values {
+		if obj, ok := v.(*slogObject); !ok || !obj.empty() {
+			return false
+		}
+	}
+	return true
+}
+
+// encode writes the object as JSON, leaving out empty objects.
+func (o *slogObject) encode(buf *bytes.Buffer) {
+	buf.WriteByte('{')
+	first := true
+	for i, key := range o.keys {
+		obj, isObj := o.values[i].(*slogObject)
+		if isObj && obj.empty() {
+			continue
+		}
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		buf.Write(marshalString(key))
+		buf.WriteByte(':')
+		if isObj {
+			obj.encode(buf)
+		} else {
+			buf.Write(o.values[i].(json.RawMessage))
+		}
+	}
+	buf.WriteByte('}')
+}
+
+// slogValue encodes a resolved value, other than a group, as JSON.
+func slogValue(v slog.Value) json.RawMessage {
+	switch v.Kind() {
+	case slog.KindString:
+		return marshalString(v.String())
+	case slog.KindInt64:
+		return json.RawMessage(strconv.FormatInt(v.Int64(), 10))
+	case slog.KindUint64:
+		return json.RawMessage(strconv.FormatUint(v.Uint64(), 10))
+	case slog.KindBool:
+		return json.RawMessage(strconv.FormatBool(v.Bool()))
+	case slog.KindDuration:
+		return marshalString(v.Duration().String())
+	case slog.KindTime:
+		return marshalString(v.Time().Format(time.RFC3339Nano))
+	}
+	value := v.Any()
+	if err, ok := value.(error); ok {
+		return marshalString(err.Error())
+	}
+	if b, err := json.Marshal(value); err == nil {
+		return b
+	}
+	return marshalString(fmt.Sprint(value))
+}
@@ -0,0 +1,71 @@
+//go:build go1.21
+// +build go1.21
+
+package jl
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlogHandler(t *testing.T) {
+	buf := &bytes.Buffer{}
+	h := NewSlogHandler(buf)
+	printer := NewLogfmtPrinter(buf)
+	printer.DisableColor = true
+	h.Printer = printer
+	h.Level = slog.LevelDebug
+
+	logger := slog.New(h).With("service", "api").WithGroup("req")
+	logger.Debug("handled", "path", "/", slog.Group("user", "id", 7), "err", errors.New("boom"), slog.Group("none"))
+	logger.WithGroup("empty").Info("no attrs")
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	assert.Len(t, lines, 2)
+	assert.Regexp(t, `^timestamp=\S+ level=DEBUG message=handled `+
+		`req=\{"path":"/","user":\{"id":7\},"err":"boom"\} service=api$`, string(lines[0]))
+	assert.Regexp(t, `^timestamp=\S+ level=INFO message=no attrs service=api$`, string(lines[1]))
+}
+
+func TestSlogHandler_Compact(t *testing.T) {
+	buf := &bytes.Buffer{}
+	h := NewSlogHandler(buf)
+	assert.False(t, h.Enabled(context.Background(), slog.LevelDebug))
+	assert.True(t, h.Enabled(context.Background(), slog.LevelWarn))
+
+	r := slog.NewRecord(time.Date(2019, 1, 1, 15, 23, 45, 0, time.UTC), slog.LevelWarn, "low disk", 0)
+	r.AddAttrs(slog.String("logger", "disk"), slog.Int("free", 3))
+	assert.NoError(t, h.Handle(context.Background(), r))
+	assert.Equal(t, "WARN 2019-01-01T15:23:45Z                 disk| low disk\n", buf.String())
+}
+
+func TestSlogHandler_Literal(t *testing.T) {
+	buf := &bytes.Buffer{}
+	printer := NewLogfmtPrinter(buf)
+	printer.DisableColor = true
+	logger := slog.New(&SlogHandler{Printer: printer})
+	logger.Info("started", "port", 8080)
+	assert.Regexp(t, `^timestamp=\S+ level=INFO message=started port=8080\n$`, buf.String())
+}
+
+func TestSlogHandler_CollidingAttrs(t *testing.T) {
+	buf := &bytes.Buffer{}
+	printer := NewLogfmtPrinter(buf)
+	printer.DisableColor = true
+	logger := slog.New(&SlogHandler{Printer: printer})
+	logger.Info("started", "message", "other", "level", "debug", "time", "later", slog.Group("req", "msg", "nested"))
+	assert.Regexp(t, `^timestamp=\S+ level=INFO message=started `+
+		`attr.level=debug attr.message=other attr.time=later req=\{"msg":"nested"\}\n$`, buf.String())
+
+	buf.Reset()
+	r := slog.NewRecord(time.Time{}, slog.LevelWarn, "no time", 0)
+	r.AddAttrs(slog.String("msg", "other"), slog.String("time", "2019-01-01T15:23:45Z"))
+	assert.NoError(t, (&SlogHandler{Printer: printer}).Handle(context.Background(), r))
+	assert.Equal(t, "level=WARN message=no time attr.msg=other attr.time=2019-01-01T15:23:45Z\n", buf.String())
+}
@@ -0,0 +1,178 @@
+package jl
+
+import (
+	"bufio"
+	"container/list"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// DefaultMaxOpenFiles is the default SplitPrinter.MaxOpenFiles.
+const DefaultMaxOpenFiles = 64
+
+// SplitMissingKey is the key of the entries that do not have the field a SplitPrinter splits by.
+const SplitMissingKey = ""
+
+// splitMissingName is the file name of the entries that do not have the field. The names of files for values of the
+// field never start with an underscore, so they cannot collide with it.
+const splitMissingName = "_none"
+
+// maxSplitNameLength is the length that keys are shortened to in file names.
+const maxSplitNameLength = 100
+
+// SplitPrinter writes entries to one file per value of a field, like a thread, logger or trace ID. Files are created
+// in Dir the first time their value is seen, replacing any existing file. To stay within the limits of the operating
+// system, at most MaxOpenFiles are kept open at a time, closing the least recently written one when needed.
+type SplitPrinter struct {
+	// Dir is the directory that files are written to.
+	Dir string
+	// Finder locates the field whose values the entries are split by.
+	Finder FieldFinder
+	// NewPrinter, if set, is called to create a printer for each file, which formats the entries. If nil, entries are
+	// written as is, one per line.
+	NewPrinter func(w io.Writer) EntryPrinter
+	// Ext is the extension of the files.
+	Ext string
+	// MaxOpenFiles is the maximum number of files open at a time.
+	MaxOpenFiles int
+
+	mu    sync.Mutex
+	files map[string]*splitFile
+	// lru orders the open files from most to least recently written.
+	lru     *list.List
+	created map[string]bool
+	err     error
+}
+
+type splitFile struct {
+	key     string
+	file    *os.File
+	buf     *bufio.Writer
+	printer EntryPrinter
+	elem    *list.Element
+}
+
+// NewSplitPrinter allocates and returns a new SplitPrinter, writing entries to files in dir by the value of the field
+// found by finder.
+func NewSplitPrinter(dir string, finder FieldFinder) *SplitPrinter {
+	return &SplitPrinter{
+		Dir:          dir,
+		Finder:       finder,
+		Ext:          ".json",
+		MaxOpenFiles: DefaultMaxOpenFiles,
+		files:        make(map[string]*splitFile),
+		lru:          list.New(),
+		created:      make(map[string]bool),
+	}
+}
+
+func (p *SplitPrinter) Print(entry *Entry) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	key := findString(p.Finder, entry)
+	f, err := p.open(key)
+	if err != nil {
+		p.setErr(err)
+		return
+	}
+	if f.printer != nil {
+		f.printer.Print(entry)
+		return
+	}
+	f.buf.Write(entry.Raw)
+	f.buf.WriteByte('\n')
+}
+
+// Flush closes all open files.
+func (p *SplitPrinter) Flush() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for p.lru.Len() > 0 {
+		p.close(p.lru.Back().Value.(*splitFile))
+	}
+}
+
+// Err returns the first error encountered while writing files, if any.
+func (p *SplitPrinter) Err() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
+
+// Path returns the path of the file that entries with the key are written to. Keys are sanitized to be safe file
+// names, and suffixed with a hash if that changed them, so that different keys never share a file.
+func (p *SplitPrinter) Path(key string) string {
+	if key == SplitMissingKey {
+		return filepath.Join(p.Dir, splitMissingName+p.Ext)
+	}
+	var name strings.Builder
+	for _, r := range key {
+		if r < 0x80 && !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			r = '_'
+		}
+		name.WriteRune(r)
+	}
+	sanitized := name.String()
+	if len(sanitized) > maxSplitNameLength {
+		n := maxSplitNameLength
+		for n > 0 && !utf8.RuneStart(sanitized[n]) {
+			n--
+		}
+		sanitized = sanitized[:n]
+	}
+	if sanitized != key || strings.HasPrefix(key, ".") || strings.HasPrefix(key, "_") {
+		h := fnv.New32a()
+		h.Write([]byte(key))
+		sanitized = fmt.Sprintf("%s-%08x", sanitized, h.Sum32())
+	}
+	return filepath.Join(p.Dir, sanitized+p.Ext)
+}
+
+// open returns the open file for the key, opening it if needed.
+func (p *SplitPrinter) open(key string) (*splitFile, error) {
+	if f, ok := p.files[key]; ok {
+		p.lru.MoveToFront(f.elem)
+		return f, nil
+	}
+	for p.MaxOpenFiles > 0 && p.lru.Len() >= p.MaxOpenFiles {
+		p.close(p.lru.Back().Value.(*splitFile))
+	}
+	flags := os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	if !p.created[key] {
+		flags |= os.O_TRUNC
+	}
+	file, err := os.OpenFile(p.Path(key), flags, 0644)
+	if err != nil {
+		return nil, err
+	}
+	p.created[key] = true
+	f := &splitFile{key: key, file: file, buf: bufio.NewWriter(file)}
+	if p.NewPrinter != nil {
+		f.printer = p.NewPrinter(f.buf)
+	}
+	f.elem = p.lru.PushFront(f)
+	p.files[key] = f
+	return f, nil
+}
+
+func (p *SplitPrinter) close(f *splitFile) {
+	if f.printer != nil {
+		Flush(f.printer)
+	}
+	p.setErr(f.buf.Flush())
+	p.setErr(f.file.Close())
+	p.lru.Remove(f.elem)
+	delete(p.files, f.key)
+}
+
+func (p *SplitPrinter) setErr(err error) {
+	if p.err == nil {
+		p.err = err
+	}
+}
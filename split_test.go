@@ -0,0 +1,91 @@
+package jl
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitPrinter_Print(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jl-split")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	logs := `{"thread":"a","msg":"1"}
+{"thread":"b","msg":"2"}
+{"thread":"c/d","msg":"3"}
+{"thread":"a","msg":"4"}
+not json`
+	printer := NewSplitPrinter(dir, ByNames("thread"))
+	printer.MaxOpenFiles = 2
+	for _, entry := range parseLines(t, logs) {
+		printer.Print(entry)
+	}
+	printer.Flush()
+	require.NoError(t, printer.Err())
+
+	read := func(key string) string {
+		b, err := ioutil.ReadFile(printer.Path(key))
+		require.NoError(t, err)
+		return string(b)
+	}
+	assert.Equal(t, `{"thread":"a","msg":"1"}
+{"thread":"a","msg":"4"}
+`, read("a"))
+	assert.Equal(t, `{"thread":"b","msg":"2"}
+`, read("b"))
+	assert.Equal(t, `{"thread":"c/d","msg":"3"}
+`, read("c/d"))
+	assert.Equal(t, "not json\n", read(SplitMissingKey))
+	assert.Equal(t, filepath.Join(dir, "_none.json"), printer.Path(SplitMissingKey))
+	assert.Equal(t, filepath.Join(dir, "a.json"), printer.Path("a"))
+	assert.Equal(t, dir, filepath.Dir(printer.Path("../c/d")))
+}
+
+func TestSplitPrinter_MissingDoesNotCollide(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jl-split")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	printer := NewSplitPrinter(dir, ByNames("thread"))
+	for _, entry := range parseLines(t, `{"thread":"_none","msg":"1"}
+{"msg":"2"}`) {
+		printer.Print(entry)
+	}
+	printer.Flush()
+	require.NoError(t, printer.Err())
+	assert.NotEqual(t, printer.Path("_none"), printer.Path(SplitMissingKey))
+	b, err := ioutil.ReadFile(printer.Path("_none"))
+	require.NoError(t, err)
+	assert.Equal(t, "{\"thread\":\"_none\",\"msg\":\"1\"}\n", string(b))
+	b, err = ioutil.ReadFile(printer.Path(SplitMissingKey))
+	require.NoError(t, err)
+	assert.Equal(t, "{\"msg\":\"2\"}\n", string(b))
+}
+
+func TestSplitPrinter_Formatted(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jl-split")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	printer := NewSplitPrinter(dir, ByNames("thread"))
+	printer.NewPrinter = func(w io.Writer) EntryPrinter {
+		lp := NewLogfmtPrinter(w)
+		lp.DisableColor = true
+		return lp
+	}
+	printer.Ext = ".log"
+	for _, entry := range parseLines(t, `{"thread":"a","msg":"1"}`) {
+		printer.Print(entry)
+	}
+	printer.Flush()
+	require.NoError(t, printer.Err())
+	b, err := ioutil.ReadFile(filepath.Join(dir, "a.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "thread=a msg=1\n", string(b))
+}
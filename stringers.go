@@ -15,7 +15,7 @@ var _ = Stringer(ErrorStringer)
 
 // DefaultStringer attempts to turn a field into string by attempting the following in order
 // 1. casting it to a string
-// 2. unmarshalling it as a json.RawMessage
+// 2. unmarshalling it as a json.RawMessage, printing objects and arrays as compact JSON
 // 3. using fmt.Sprintf("%v", input)
 func DefaultStringer(ctx *Context, v interface{}) string {
 	var s string
@@ -23,8 +23,11 @@ func DefaultStringer(ctx *Context, v interface{}) string {
 		s = tmp
 	} else if rawMsg, ok := v.(json.RawMessage); ok {
 		var unmarshaled interface{}
+		var compacted bytes.Buffer
 		if err := json.Unmarshal(rawMsg, &unmarshaled); err != nil {
 			s = string(rawMsg)
+		} else if isObjectOrArray(unmarshaled) && json.Compact(&compacted, rawMsg) == nil {
+			s = compacted.String()
 		} else {
 			s = fmt.Sprintf("%v", unmarshaled)
 		}
@@ -34,9 +37,16 @@ func DefaultStringer(ctx *Context, v interface{}) string {
 	return s
 }
 
-// ErrorStringer stringifies LogrusError to a multiline string. If the field is not a LogrusError, it falls back
-// to the DefaultStringer.
+// ErrorStringer stringifies LogrusError to a multiline string, and Continuation to its error followed by its lines
+// below it. If the field is neither, it falls back to the DefaultStringer.
 func ErrorStringer(ctx *Context, v interface{}) string {
+	if c, ok := v.(Continuation); ok {
+		var s string
+		if c.Error != nil {
+			s = ErrorStringer(ctx, c.Error)
+		}
+		return s + "\n" + c.Lines
+	}
 	w := &bytes.Buffer{}
 	if logrusErr, ok := v.(LogrusError); ok {
 		w.WriteString("\n  ")
@@ -51,3 +61,11 @@ func ErrorStringer(ctx *Context, v interface{}) string {
 		return DefaultStringer(ctx, v)
 	}
 }
+
+func isObjectOrArray(v interface{}) bool {
+	switch v.(type) {
+	case map[string]interface{}, []interface{}:
+		return true
+	}
+	return false
+}
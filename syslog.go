@@ -0,0 +1,197 @@
+package jl
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// syslogSeverities are the names of the syslog severities, which NormalizeLevel understands.
+var syslogSeverities = []string{"emergency", "alert", "critical", "error", "warning", "notice", "info", "debug"}
+
+// SyslogMessage is a message in the syslog protocol, either RFC 5424 or the older BSD format of RFC 3164.
+type SyslogMessage struct {
+	Facility int
+	Severity int
+	// Timestamp is zero if the message has none.
+	Timestamp time.Time
+	Hostname  string
+	AppName   string
+	ProcID    string
+	MsgID     string
+	// StructuredData holds the RFC 5424 structured data elements as is, including the brackets.
+	StructuredData string
+	Message        string
+}
+
+// SyslogDecoder decodes syslog messages into the entries returned by SyslogMessage.Entry.
+var SyslogDecoder Decoder = DecoderFunc(func(line []byte) (*Entry, bool) {
+	m, ok := ParseSyslog(line)
+	if !ok {
+		return nil, false
+	}
+	return m.Entry(), true
+})
+
+// ParseSyslog parses a syslog message, returning false if it is not one. The message may be in the RFC 5424 format, or
+// in the BSD format of RFC 3164. Fields that are missing or nil are left empty.
+func ParseSyslog(line []byte) (*SyslogMessage, bool) {
+	s := strings.TrimRight(string(line), "\r\n")
+	if !strings.HasPrefix(s, "<") {
+		return nil, false
+	}
+	end := strings.IndexByte(s, '>')
+	if end < 2 || end > 4 {
+		return nil, false
+	}
+	pri, err := strconv.Atoi(s[1:end])
+	if err != nil || pri > 191 {
+		return nil, false
+	}
+	m := &SyslogMessage{Facility: pri / 8, Severity: pri % 8}
+	s = s[end+1:]
+	if strings.HasPrefix(s, "1 ") {
+		return m, m.parse5424(s[2:])
+	}
+	return m, m.parse3164(s)
+}
+
+// parse5424 parses the rest of an RFC 5424 message after the version:
+// TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA [MSG]
+func (m *SyslogMessage) parse5424(s string) bool {
+	fields := strings.SplitN(s, " ", 6)
+	if len(fields) < 6 {
+		return false
+	}
+	if fields[0] != "-" {
+		ts, err := time.Parse(time.RFC3339Nano, fields[0])
+		if err != nil {
+			return false
+		}
+		m.Timestamp = ts
+	}
+	m.Hostname = nilValue(fields[1])
+	m.AppName = nilValue(fields[2])
+	m.ProcID = nilValue(fields[3])
+	m.MsgID = nilValue(fields[4])
+	rest := fields[5]
+	if strings.HasPrefix(rest, "-") {
+		rest = rest[1:]
+	} else {
+		n := structuredDataLength(rest)
+		if n == 0 {
+			return false
+		}
+		m.StructuredData = rest[:n]
+		rest = rest[n:]
+	}
+	rest = strings.TrimPrefix(rest, " ")
+	// Messages may start with a byte order mark to show they are UTF-8.
+	m.Message = strings.TrimPrefix(rest, "\ufeff")
+	return true
+}
+
+// structuredDataLength returns the length of the structured data elements at the start of s, or 0 if there are none.
+func structuredDataLength(s string) int {
+	i := 0
+	for i < len(s) && s[i] == '[' {
+		quoted := false
+		for i++; i < len(s); i++ {
+			c := s[i]
+			if c == '\\' && quoted {
+				i++
+			} else if c == '"' {
+				quoted = !quoted
+			} else if c == ']' && !quoted {
+				break
+			}
+		}
+		if i >= len(s) {
+			return 0
+		}
+		i++
+	}
+	return i
+}
+
+func nilValue(s string) string {
+	if s == "-" {
+		return ""
+	}
+	return s
+}
+
+// parse3164 parses the rest of an RFC 3164 message after the priority: TIMESTAMP HOSTNAME TAG[PID]: MSG. Since many
+// senders leave out the hostname, the first word is only taken to be the hostname if it does not look like a tag.
+func (m *SyslogMessage) parse3164(s string) bool {
+	if len(s) >= len(time.Stamp) {
+		if ts, err := time.ParseInLocation(time.Stamp, s[:len(time.Stamp)], time.Local); err == nil {
+			m.Timestamp = withYear(ts, time.Now())
+			s = strings.TrimPrefix(s[len(time.Stamp):], " ")
+		}
+	}
+	if i := strings.IndexByte(s, ' '); i > 0 && !strings.ContainsAny(s[:i], ":[") {
+		m.Hostname = s[:i]
+		s = s[i+1:]
+	}
+	if i := strings.IndexAny(s, ":[ "); i > 0 && s[i] != ' ' {
+		m.AppName = s[:i]
+		s = s[i:]
+		if strings.HasPrefix(s, "[") {
+			if end := strings.IndexByte(s, ']'); end > 0 {
+				m.ProcID = s[1:end]
+				s = s[end+1:]
+			}
+		}
+		s = strings.TrimPrefix(s, ":")
+	}
+	m.Message = strings.TrimPrefix(s, " ")
+	return true
+}
+
+// withYear sets the year of a timestamp without one to the year of now, or the year before if that would put it more
+// than a day in the future.
+func withYear(ts, now time.Time) time.Time {
+	ts = ts.AddDate(now.Year()-ts.Year(), 0, 0)
+	if ts.After(now.Add(24 * time.Hour)) {
+		ts = ts.AddDate(-1, 0, 0)
+	}
+	return ts
+}
+
+// Entry converts the message to an Entry. If the message is a JSON object, the entry is the object. Otherwise the
+// entry is a JSON object with the timestamp, level, host, app, pid and message.
+func (m *SyslogMessage) Entry() *Entry {
+	msg := strings.TrimSpace(m.Message)
+	if strings.HasPrefix(msg, "{") {
+		if entry := NewEntry([]byte(msg)); entry.Partials != nil {
+			return entry
+		}
+	}
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	add := func(key, value string) {
+		if value == "" {
+			return
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		buf.Write(marshalString(key))
+		buf.WriteByte(':')
+		buf.Write(marshalString(value))
+	}
+	if !m.Timestamp.IsZero() {
+		add("timestamp", m.Timestamp.Format(time.RFC3339Nano))
+	}
+	add("level", syslogSeverities[m.Severity])
+	add("host", m.Hostname)
+	add("app", m.AppName)
+	add("pid", m.ProcID)
+	add("msgid", m.MsgID)
+	add("structuredData", m.StructuredData)
+	add("message", m.Message)
+	buf.WriteByte('}')
+	return NewEntry(buf.Bytes())
+}
@@ -0,0 +1,60 @@
+package jl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSyslog(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		entry string
+	}{{
+		name:  "rfc5424",
+		line:  `<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut="3" eventSource="Appli\]cation"] An application event log entry`,
+		entry: `{"timestamp":"2003-10-11T22:14:15.003Z","level":"notice","host":"mymachine.example.com","app":"evntslog","msgid":"ID47","structuredData":"[exampleSDID@32473 iut=\"3\" eventSource=\"Appli\\]cation\"]","message":"An application event log entry"}`,
+	}, {
+		name:  "rfc5424_nil",
+		line:  "<11>1 - - - - - -",
+		entry: `{"level":"error"}`,
+	}, {
+		name:  "rfc5424_json",
+		line:  "<14>1 2003-10-11T22:14:15Z host app 42 - - \ufeff{\"level\":\"info\",\"msg\":\"hi\"}",
+		entry: `{"level":"info","msg":"hi"}`,
+	}, {
+		name:  "rfc3164_no_host",
+		line:  `<13>app[7]: started`,
+		entry: `{"level":"notice","app":"app","pid":"7","message":"started"}`,
+	}, {
+		name:  "rfc3164_host",
+		line:  `<34>mymachine su: 'su root' failed`,
+		entry: `{"level":"critical","host":"mymachine","app":"su","message":"'su root' failed"}`,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m, ok := ParseSyslog([]byte(test.line))
+			require.True(t, ok)
+			assert.Equal(t, test.entry, string(m.Entry().Raw))
+		})
+	}
+
+	_, ok := ParseSyslog([]byte("not syslog"))
+	assert.False(t, ok)
+	_, ok = ParseSyslog([]byte("<999>1 - - - - - -"))
+	assert.False(t, ok)
+}
+
+func TestParseSyslog_RFC3164Timestamp(t *testing.T) {
+	m, ok := ParseSyslog([]byte("<34>Oct  1 22:14:15 mymachine su: failed"))
+	require.True(t, ok)
+	assert.Equal(t, "mymachine", m.Hostname)
+	assert.Equal(t, "Oct  1 22:14:15", m.Timestamp.Format(time.Stamp))
+
+	now := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	ts := time.Date(0, time.December, 31, 23, 0, 0, 0, time.UTC)
+	assert.Equal(t, 2018, withYear(ts, now).Year())
+}
@@ -0,0 +1,196 @@
+package jl
+
+import (
+	"sort"
+)
+
+// Theme is a set of styles used by the printers to color log output.
+type Theme struct {
+	// Name of the theme, as used in Themes.
+	Name string
+	// Levels maps lower-cased log levels to the style used for them.
+	Levels map[string]Style
+	// Palette is the set of styles that field values like thread and logger names are assigned from.
+	Palette []Style
+}
+
+// DarkTheme is the default theme, tuned for terminals with dark backgrounds. It uses the 16 standard colors, as
+// initially set in AllColors and LevelColors. It is built when the package is initialized, so later changes to those
+// variables do not affect it.
+var DarkTheme = &Theme{
+	Name:    "dark",
+	Levels:  colorStyleMap(LevelColors),
+	Palette: colorStyles(AllColors),
+}
+
+// LightTheme is tuned for terminals with light backgrounds, avoiding the pale colors that are unreadable on white.
+var LightTheme = &Theme{
+	Name: "light",
+	Levels: map[string]Style{
+		"trace":   {Fg: Color256(244)},
+		"debug":   {Fg: Color256(244)},
+		"info":    {Fg: Color256(28)},
+		"warn":    {Fg: Color256(130)},
+		"warning": {Fg: Color256(130)},
+		"error":   {Fg: Color256(160), Bold: true},
+		"fatal":   {Fg: Color256(160), Bold: true},
+		"panic":   {Fg: Color256(160), Bold: true},
+	},
+	Palette: []Style{
+		{Fg: Color256(22)},
+		{Fg: Color256(94)},
+		{Fg: Color256(19)},
+		{Fg: Color256(90)},
+		{Fg: Color256(30)},
+		{Fg: Color256(58)},
+		{Fg: Color256(25)},
+		{Fg: Color256(127)},
+		{Fg: Color256(23)},
+		{Fg: Color256(166)},
+		{Fg: Color256(54)},
+		{Fg: Color256(240)},
+	},
+}
+
+// Solarized accent colors, see https://ethanschoonover.com/solarized/.
+var (
+	solarizedBase01  = RGB(0x58, 0x6e, 0x75)
+	solarizedBase1   = RGB(0x93, 0xa1, 0xa1)
+	solarizedYellow  = RGB(0xb5, 0x89, 0x00)
+	solarizedOrange  = RGB(0xcb, 0x4b, 0x16)
+	solarizedRed     = RGB(0xdc, 0x32, 0x2f)
+	solarizedMagenta = RGB(0xd3, 0x36, 0x82)
+	solarizedViolet  = RGB(0x6c, 0x71, 0xc4)
+	solarizedBlue    = RGB(0x26, 0x8b, 0xd2)
+	solarizedCyan    = RGB(0x2a, 0xa1, 0x98)
+	solarizedGreen   = RGB(0x85, 0x99, 0x00)
+)
+
+// SolarizedTheme uses the Solarized accent colors, which are readable on both the light and dark Solarized
+// backgrounds.
+var SolarizedTheme = &Theme{
+	Name: "solarized",
+	Levels: map[string]Style{
+		"trace":   {Fg: solarizedBase01},
+		"debug":   {Fg: solarizedBase01},
+		"info":    {Fg: solarizedGreen},
+		"warn":    {Fg: solarizedYellow},
+		"warning": {Fg: solarizedYellow},
+		"error":   {Fg: solarizedRed, Bold: true},
+		"fatal":   {Fg: solarizedRed, Bold: true},
+		"panic":   {Fg: solarizedRed, Bold: true},
+	},
+	Palette: []Style{
+		{Fg: solarizedBlue},
+		{Fg: solarizedCyan},
+		{Fg: solarizedViolet},
+		{Fg: solarizedMagenta},
+		{Fg: solarizedOrange},
+		{Fg: solarizedYellow},
+		{Fg: solarizedGreen},
+		{Fg: solarizedBase1},
+	},
+}
+
+// HighContrastTheme uses bold, bright colors and marks errors with a background color so they stand out.
+var HighContrastTheme = &Theme{
+	Name: "high-contrast",
+	Levels: map[string]Style{
+		"trace":   {Fg: HiWhite},
+		"debug":   {Fg: HiWhite},
+		"info":    {Fg: HiGreen, Bold: true},
+		"warn":    {Fg: Black, Bg: HiYellow, Bold: true},
+		"warning": {Fg: Black, Bg: HiYellow, Bold: true},
+		"error":   {Fg: HiWhite, Bg: Red, Bold: true},
+		"fatal":   {Fg: HiWhite, Bg: Red, Bold: true},
+		"panic":   {Fg: HiWhite, Bg: Red, Bold: true},
+	},
+	Palette: []Style{
+		{Fg: HiCyan, Bold: true},
+		{Fg: HiYellow, Bold: true},
+		{Fg: HiMagenta, Bold: true},
+		{Fg: HiGreen, Bold: true},
+		{Fg: HiBlue, Bold: true},
+		{Fg: HiWhite, Bold: true},
+		{Fg: HiCyan, Underline: true},
+		{Fg: HiYellow, Underline: true},
+		{Fg: HiMagenta, Underline: true},
+		{Fg: HiGreen, Underline: true},
+	},
+}
+
+// Okabe-Ito colors, a palette that stays distinguishable for the common forms of color blindness.
+var (
+	okabeItoOrange    = RGB(0xe6, 0x9f, 0x00)
+	okabeItoSkyBlue   = RGB(0x56, 0xb4, 0xe9)
+	okabeItoGreen     = RGB(0x00, 0x9e, 0x73)
+	okabeItoYellow    = RGB(0xf0, 0xe4, 0x42)
+	okabeItoBlue      = RGB(0x00, 0x72, 0xb2)
+	okabeItoVermilion = RGB(0xd5, 0x5e, 0x00)
+	okabeItoPurple    = RGB(0xcc, 0x79, 0xa7)
+	okabeItoGray      = RGB(0x99, 0x99, 0x99)
+)
+
+// ColorblindTheme uses the Okabe-Ito palette, and does not rely on telling red and green apart: errors are also bold
+// and warnings underlined.
+var ColorblindTheme = &Theme{
+	Name: "colorblind-safe",
+	Levels: map[string]Style{
+		"trace":   {Fg: okabeItoGray},
+		"debug":   {Fg: okabeItoGray},
+		"info":    {Fg: okabeItoSkyBlue},
+		"warn":    {Fg: okabeItoOrange, Underline: true},
+		"warning": {Fg: okabeItoOrange, Underline: true},
+		"error":   {Fg: okabeItoVermilion, Bold: true},
+		"fatal":   {Fg: okabeItoVermilion, Bold: true},
+		"panic":   {Fg: okabeItoVermilion, Bold: true},
+	},
+	Palette: []Style{
+		{Fg: okabeItoSkyBlue},
+		{Fg: okabeItoOrange},
+		{Fg: okabeItoGreen},
+		{Fg: okabeItoYellow},
+		{Fg: okabeItoBlue},
+		{Fg: okabeItoPurple},
+		{Fg: okabeItoVermilion},
+		{Fg: okabeItoGray},
+	},
+}
+
+// DefaultTheme is the theme used by printers that are not given one.
+var DefaultTheme = DarkTheme
+
+// Themes holds the built-in themes by name.
+var Themes = map[string]*Theme{
+	DarkTheme.Name:         DarkTheme,
+	LightTheme.Name:        LightTheme,
+	SolarizedTheme.Name:    SolarizedTheme,
+	HighContrastTheme.Name: HighContrastTheme,
+	ColorblindTheme.Name:   ColorblindTheme,
+}
+
+// ThemeNames returns the sorted names of the built-in themes.
+func ThemeNames() []string {
+	names := make([]string, 0, len(Themes))
+	for name := range Themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func colorStyles(colors []Color) []Style {
+	styles := make([]Style, len(colors))
+	for i, c := range colors {
+		styles[i] = Style{Fg: c}
+	}
+	return styles
+}
+
+func colorStyleMap(colors map[string]Color) map[string]Style {
+	styles := make(map[string]Style, len(colors))
+	for k, c := range colors {
+		styles[k] = Style{Fg: c}
+	}
+	return styles
+}
@@ -0,0 +1,68 @@
+package jl
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimestampFinder finds the timestamp of an entry, using the same field names as DefaultCompactPrinterFieldFmt, and
+// "@timestamp" as written by Logstash and Elasticsearch.
+var TimestampFinder = ByNames("timestamp", "time", "ts", "@timestamp")
+
+// timeLayouts are the layouts tried in order by ParseTime.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04:05,999999999",
+	"02/Jan/2006:15:04:05 -0700",
+	time.RFC1123Z,
+	time.RFC1123,
+	time.ANSIC,
+	time.Stamp,
+}
+
+// ParseTime parses a timestamp in one of the common log formats: RFC 3339 and variations of it, the Apache common log
+// format, or a Unix timestamp in seconds, milliseconds, microseconds or nanoseconds. Timestamps without a time zone are
+// assumed to be in local time.
+func ParseTime(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return unixTime(f), true
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// unixTime converts a Unix timestamp to a time, guessing its unit from its magnitude.
+func unixTime(f float64) time.Time {
+	switch {
+	case f > 1e17:
+		return time.Unix(0, int64(f))
+	case f > 1e14:
+		return time.Unix(0, int64(f*1e3))
+	case f > 1e11:
+		return time.Unix(0, int64(f*1e6))
+	default:
+		return time.Unix(0, int64(f*1e9))
+	}
+}
+
+// EntryTime returns the time of the entry, found with TimestampFinder, and false if it has none or it cannot be parsed.
+func EntryTime(entry *Entry) (time.Time, bool) {
+	v := TimestampFinder(entry)
+	if v == nil {
+		return time.Time{}, false
+	}
+	if raw, ok := v.(json.RawMessage); ok {
+		return ParseTime(toString(raw))
+	}
+	return ParseTime(DefaultStringer(&Context{}, v))
+}
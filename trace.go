@@ -0,0 +1,307 @@
+package jl
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// TraceIDFinder finds the trace ID of an entry.
+	TraceIDFinder = ByNames("traceId", "trace_id", "traceID", "trace.id", "logging.googleapis.com/trace")
+	// SpanIDFinder finds the span ID of an entry.
+	SpanIDFinder = ByNames("spanId", "span_id", "spanID", "span.id", "logging.googleapis.com/spanId")
+	// ParentSpanIDFinder finds the ID of the parent of the entry's span.
+	ParentSpanIDFinder = ByNames("parentSpanId", "parent_span_id", "parentSpanID", "parent.id", "parentId")
+)
+
+// DefaultTraceIdleTimeout is the default time TracePrinter waits for more entries of a trace before printing it.
+const DefaultTraceIdleTimeout = 10 * time.Second
+
+// TracePrinter buffers entries and prints them grouped by trace. Each trace is printed as a block headed by a summary
+// with the number of entries, the time between the first and last entries and the number of entries at each level.
+// Within a trace, entries are ordered and indented by their span hierarchy. Entries that are not part of a trace are
+// printed right away.
+type TracePrinter struct {
+	// Out is the writer where traces are written to.
+	Out io.Writer
+	// Printer formats entries. It must write to Buffer.
+	Printer EntryPrinter
+	// Buffer is the writer that Printer writes to.
+	Buffer *bytes.Buffer
+	// DisableColor disables ANSI escape sequences in trace headers.
+	DisableColor bool
+	// ColorMode is the color mode of the terminal. Colors that it does not support are downgraded.
+	ColorMode ColorMode
+	// ErrorsOnly prints only the traces that contain an entry with an error level.
+	ErrorsOnly bool
+	// IdleTimeout is how long to wait for more entries of a trace before printing it. If zero, traces are printed
+	// only when the printer is flushed.
+	IdleTimeout time.Duration
+	// TraceFinder, SpanFinder and ParentSpanFinder locate the IDs of the trace, span and parent span of an entry.
+	TraceFinder, SpanFinder, ParentSpanFinder FieldFinder
+
+	mu     sync.Mutex
+	traces map[string]*trace
+	// order holds the IDs of the buffered traces, in the order they were first seen.
+	order []string
+}
+
+type trace struct {
+	id      string
+	entries []*traceEntry
+	timer   *time.Timer
+}
+
+type traceEntry struct {
+	entry        *Entry
+	span, parent string
+}
+
+// NewTracePrinter allocates and returns a new TracePrinter. newPrinter is called once to create the printer used to
+// format entries.
+func NewTracePrinter(w io.Writer, newPrinter func(w io.Writer) EntryPrinter) *TracePrinter {
+	buf := &bytes.Buffer{}
+	return &TracePrinter{
+		Out:              w,
+		Printer:          newPrinter(buf),
+		Buffer:           buf,
+		IdleTimeout:      DefaultTraceIdleTimeout,
+		TraceFinder:      TraceIDFinder,
+		SpanFinder:       SpanIDFinder,
+		ParentSpanFinder: ParentSpanIDFinder,
+		traces:           make(map[string]*trace),
+	}
+}
+
+func (p *TracePrinter) Print(entry *Entry) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	id := findString(p.TraceFinder, entry)
+	if id == "" {
+		p.printEntry(entry, 0)
+		return
+	}
+	t, ok := p.traces[id]
+	if !ok {
+		t = &trace{id: id}
+		p.traces[id] = t
+		p.order = append(p.order, id)
+		if p.IdleTimeout > 0 {
+			t.timer = time.AfterFunc(p.IdleTimeout, func() {
+				p.mu.Lock()
+				defer p.mu.Unlock()
+				if p.traces[id] == t {
+					p.printTrace(t)
+				}
+			})
+		}
+	} else if t.timer != nil {
+		t.timer.Reset(p.IdleTimeout)
+	}
+	t.entries = append(t.entries, &traceEntry{
+		entry:  entry,
+		span:   findString(p.SpanFinder, entry),
+		parent: findString(p.ParentSpanFinder, entry),
+	})
+}
+
+// Flush prints all buffered traces.
+func (p *TracePrinter) Flush() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for len(p.order) > 0 {
+		p.printTrace(p.traces[p.order[0]])
+	}
+}
+
+// printTrace prints the trace and removes it from the buffer.
+func (p *TracePrinter) printTrace(t *trace) {
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+	delete(p.traces, t.id)
+	for i, id := range p.order {
+		if id == t.id {
+			p.order = append(p.order[:i], p.order[i+1:]...)
+			break
+		}
+	}
+	if p.ErrorsOnly && !t.hasError() {
+		return
+	}
+	header := "── trace " + t.id + " · " + t.summary() + " ──"
+	if !p.DisableColor {
+		header = Style{Bold: true}.Render(p.ColorMode, header)
+	}
+	fmt.Fprintln(p.Out, header)
+	for _, te := range t.ordered() {
+		p.printEntry(te.entry, t.depth(te.span)+1)
+	}
+}
+
+// printEntry formats the entry with Printer and writes it to Out, indented by depth levels.
+func (p *TracePrinter) printEntry(entry *Entry, depth int) {
+	p.Buffer.Reset()
+	p.Printer.Print(entry)
+	if depth == 0 {
+		p.Out.Write(p.Buffer.Bytes())
+		return
+	}
+	indent := strings.Repeat("  ", depth)
+	formatted := strings.TrimSuffix(p.Buffer.String(), "\n")
+	io.WriteString(p.Out, indent+strings.Replace(formatted, "\n", "\n"+indent, -1)+"\n")
+}
+
+func (t *trace) hasError() bool {
+	for _, te := range t.entries {
+		if IsErrorLevel(EntryLevel(te.entry)) {
+			return true
+		}
+	}
+	return false
+}
+
+// summary describes the number of entries, their duration and the number at each level.
+func (t *trace) summary() string {
+	parts := []string{pluralize(len(t.entries), "entry", "entries")}
+	var first, last time.Time
+	levels := make(map[string]int)
+	for _, te := range t.entries {
+		if ts, ok := EntryTime(te.entry); ok {
+			if first.IsZero() || ts.Before(first) {
+				first = ts
+			}
+			if ts.After(last) {
+				last = ts
+			}
+		}
+		if level := EntryLevel(te.entry); level != "" {
+			levels[level]++
+		}
+	}
+	if !first.IsZero() {
+		parts = append(parts, last.Sub(first).String())
+	}
+	for _, level := range sortLevels(levels) {
+		parts = append(parts, fmt.Sprintf("%s %d", strings.ToUpper(level), levels[level]))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// depth returns the number of ancestors of the span that are part of the trace.
+func (t *trace) depth(span string) int {
+	parents := t.parents()
+	depth := 0
+	for seen := map[string]bool{span: true}; ; depth++ {
+		parent, ok := parents[span]
+		if !ok || seen[parent] {
+			return depth
+		}
+		seen[parent] = true
+		span = parent
+	}
+}
+
+// parents maps each span in the trace to its parent, if the parent is also part of the trace.
+func (t *trace) parents() map[string]string {
+	spans := make(map[string]bool)
+	for _, te := range t.entries {
+		spans[te.span] = true
+	}
+	parents := make(map[string]string)
+	for _, te := range t.entries {
+		if te.span != "" && te.parent != "" && te.parent != te.span && spans[te.parent] {
+			parents[te.span] = te.parent
+		}
+	}
+	return parents
+}
+
+// ordered returns the entries of the trace in depth first order of their spans. The entries of a span are kept in the
+// order they were logged and come before the entries of its child spans.
+func (t *trace) ordered() []*traceEntry {
+	parents := t.parents()
+	bySpan := make(map[string][]*traceEntry)
+	children := make(map[string][]string)
+	var roots []string
+	for _, te := range t.entries {
+		if _, ok := bySpan[te.span]; !ok {
+			if parent, ok := parents[te.span]; ok {
+				children[parent] = append(children[parent], te.span)
+			} else {
+				roots = append(roots, te.span)
+			}
+		}
+		bySpan[te.span] = append(bySpan[te.span], te)
+	}
+	var ordered []*traceEntry
+	visited := make(map[string]bool)
+	var visit func(span string)
+	visit = func(span string) {
+		if visited[span] {
+			return
+		}
+		visited[span] = true
+		ordered = append(ordered, bySpan[span]...)
+		for _, child := range children[span] {
+			visit(child)
+		}
+	}
+	for _, root := range roots {
+		visit(root)
+	}
+	// Spans whose ancestors form a cycle are not reachable from a root.
+	for _, te := range t.entries {
+		visit(te.span)
+	}
+	return ordered
+}
+
+// sortLevels returns the levels in the map from least to most severe, followed by unknown levels in alphabetical order.
+func sortLevels(counts map[string]int) []string {
+	var levels, unknown []string
+	for _, level := range Levels {
+		if _, ok := counts[level]; ok {
+			levels = append(levels, level)
+		}
+	}
+	for level := range counts {
+		if !isKnownLevel(level) {
+			unknown = append(unknown, level)
+		}
+	}
+	sort.Strings(unknown)
+	return append(levels, unknown...)
+}
+
+func isKnownLevel(level string) bool {
+	for _, l := range Levels {
+		if l == level {
+			return true
+		}
+	}
+	return false
+}
+
+func findString(finder FieldFinder, entry *Entry) string {
+	if finder == nil || entry.Partials == nil {
+		return ""
+	}
+	v := finder(entry)
+	if v == nil {
+		return ""
+	}
+	return DefaultStringer(&Context{}, v)
+}
+
+func pluralize(n int, singular, plural string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, singular)
+	}
+	return fmt.Sprintf("%d %s", n, plural)
+}
@@ -0,0 +1,113 @@
+package jl
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const traceLogs = `{"timestamp":"2019-01-01T15:23:45Z","level":"info","traceId":"t1","spanId":"a","message":"request received"}
+{"timestamp":"2019-01-01T15:23:45Z","level":"info","message":"unrelated"}
+{"timestamp":"2019-01-01T15:23:46Z","level":"debug","traceId":"t2","spanId":"x","message":"other trace"}
+{"timestamp":"2019-01-01T15:23:46.5Z","level":"info","traceId":"t1","spanId":"b","parentSpanId":"a","message":"querying db"}
+{"timestamp":"2019-01-01T15:23:47Z","level":"error","traceId":"t1","spanId":"c","parentSpanId":"b","message":"db timeout"}
+{"timestamp":"2019-01-01T15:23:48Z","level":"info","traceId":"t1","spanId":"a","message":"responded 500"}`
+
+func TestTracePrinter_Print(t *testing.T) {
+	tests := []struct {
+		name       string
+		errorsOnly bool
+		formatted  string
+	}{{
+		name: "all traces",
+		formatted: `message=unrelated
+── trace t1 · 4 entries · 3s · INFO 3 · ERROR 1 ──
+  message=request received
+  message=responded 500
+    message=querying db
+      message=db timeout
+── trace t2 · 1 entry · 0s · DEBUG 1 ──
+  message=other trace
+`,
+	}, {
+		name:       "errors only",
+		errorsOnly: true,
+		formatted: `message=unrelated
+── trace t1 · 4 entries · 3s · INFO 3 · ERROR 1 ──
+  message=request received
+  message=responded 500
+    message=querying db
+      message=db timeout
+`,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			printer := NewTracePrinter(buf, func(w io.Writer) EntryPrinter {
+				lp := NewLogfmtPrinter(w)
+				lp.DisableColor = true
+				return messageOnly{lp}
+			})
+			printer.DisableColor = true
+			printer.ErrorsOnly = test.errorsOnly
+			printer.IdleTimeout = 0
+			for _, line := range strings.Split(traceLogs, "\n") {
+				entry := &Entry{Raw: []byte(line)}
+				require.NoError(t, json.Unmarshal(entry.Raw, &entry.Partials))
+				printer.Print(entry)
+			}
+			printer.Flush()
+			assert.Equal(t, test.formatted, buf.String())
+		})
+	}
+}
+
+func TestTracePrinter_IdleTimeout(t *testing.T) {
+	buf := &bytes.Buffer{}
+	printer := NewTracePrinter(buf, func(w io.Writer) EntryPrinter {
+		cp := NewCompactPrinter(w)
+		cp.DisableColor = true
+		return cp
+	})
+	printer.DisableColor = true
+	printer.IdleTimeout = 10 * time.Millisecond
+	entry := &Entry{Raw: []byte(`{"traceId":"t1"}`)}
+	require.NoError(t, json.Unmarshal(entry.Raw, &entry.Partials))
+	printer.Print(entry)
+	time.Sleep(50 * time.Millisecond)
+	printer.mu.Lock()
+	defer printer.mu.Unlock()
+	assert.Equal(t, "── trace t1 · 1 entry ──\n   t1|\n", buf.String())
+}
+
+func TestTracePrinter_ColorMode(t *testing.T) {
+	for _, mode := range []ColorMode{ColorModeNone, ColorMode16} {
+		buf := &bytes.Buffer{}
+		printer := NewTracePrinter(buf, func(w io.Writer) EntryPrinter { return &sourcePrinter{} })
+		printer.ColorMode = mode
+		entry := &Entry{Raw: []byte(`{"traceId":"t1"}`)}
+		require.NoError(t, json.Unmarshal(entry.Raw, &entry.Partials))
+		printer.Print(entry)
+		printer.Flush()
+		header := "── trace t1 · 1 entry ──"
+		if mode != ColorModeNone {
+			header = "\x1b[1m" + header + "\x1b[0m"
+		}
+		assert.Equal(t, header, strings.SplitN(buf.String(), "\n", 2)[0])
+	}
+}
+
+// messageOnly prints only the message field of entries.
+type messageOnly struct {
+	printer EntryPrinter
+}
+
+func (p messageOnly) Print(entry *Entry) {
+	p.printer.Print(&Entry{Partials: map[string]json.RawMessage{"message": entry.Partials["message"]}})
+}
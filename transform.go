@@ -4,19 +4,34 @@ import (
 	"bytes"
 	"fmt"
 	"strings"
-	"unicode/utf8"
 )
 
 // Context provides the current transformation context, to be used by Transformers and Stringers.
 type Context struct {
 	// The original string before any transformations were applied.
 	Original string
+	// Name of the field being transformed, if known.
+	Field string
+	// The value of the field being transformed, as found in Entry, if known.
+	Value interface{}
+	// The entry being printed, if known.
+	Entry *Entry
 	// Indicates that terminal color escape sequences should be disabled.
 	DisableColor bool
+	// The colors supported by the terminal.
+	ColorMode ColorMode
 	// Indicates that fields should not be truncated.
 	DisableTruncate bool
 }
 
+// render applies the style to text, using the color mode of the context.
+func (ctx *Context) render(s Style, text string) string {
+	if ctx.DisableColor {
+		return text
+	}
+	return s.Render(ctx.ColorMode, text)
+}
+
 // Transformer transforms a string and returns the result.
 type Transformer interface {
 	Transform(ctx *Context, input string) string
@@ -36,43 +51,39 @@ var (
 	LowerCase = TransformFunc(strings.ToLower)
 )
 
-// Truncate truncates the string to the a requested number of digits.
+// Truncate truncates the string to a requested number of columns.
 type Truncate int
 
 func (t Truncate) Transform(ctx *Context, input string) string {
 	if ctx.DisableTruncate {
 		return input
 	}
-	if utf8.RuneCountInString(input) <= int(t) {
-		return input
-	}
-	return input[:t]
+	head, _ := cutWidth(input, int(t))
+	return head
 }
 
 // Ellipsize replaces characters in the middle of the string with a single "…" character so that it fits within the
-// requested length.
+// requested number of columns.
 type Ellipsize int
 
 func (remain Ellipsize) Transform(ctx *Context, input string) string {
 	if ctx.DisableTruncate {
 		return input
 	}
-	length := utf8.RuneCountInString(input)
-	if length <= int(remain) {
+	if DisplayWidth(input) <= int(remain) {
 		return input
 	}
 	remain -= 1 // account for the ellipsis
-	chomped := length - int(remain)
-	start := int(remain)/2
-	end := start + chomped
-	return input[:start] + "…" + input[end:]
+	start, _ := cutWidth(input, int(remain)/2)
+	end := suffixWidth(input, int(remain)-DisplayWidth(start))
+	return start + "…" + end
 }
 
-// LeftPad pads the left side of the string with spaces so that the string becomes the requested length.
+// LeftPad pads the left side of the string with spaces so that the string becomes the requested number of columns wide.
 type LeftPad int
 
 func (t LeftPad) Transform(ctx *Context, input string) string {
-	spaces := int(t) - utf8.RuneCountInString(input)
+	spaces := int(t) - DisplayWidth(input)
 	if spaces <= 0 {
 		return input
 	}
@@ -84,11 +95,12 @@ func (t LeftPad) Transform(ctx *Context, input string) string {
 	return buf.String()
 }
 
-// LeftPad pads the right side of the string with spaces so that the string becomes the requested length.
+// RightPad pads the right side of the string with spaces so that the string becomes the requested number of columns
+// wide.
 type RightPad int
 
 func (t RightPad) Transform(ctx *Context, input string) string {
-	pad := int(t) - utf8.RuneCountInString(input)
+	pad := int(t) - DisplayWidth(input)
 	if pad <= 0 {
 		return input
 	}
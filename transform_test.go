@@ -0,0 +1,56 @@
+package jl
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransformers_DisplayWidth(t *testing.T) {
+	tests := []struct {
+		name        string
+		transformer Transformer
+		input       string
+		output      string
+	}{
+		{"truncate ascii", Truncate(4), "error", "erro"},
+		{"truncate wide", Truncate(5), "注文サービス", "注文"},
+		{"truncate combining", Truncate(2), "e\u0301te\u0301", "e\u0301t"},
+		{"ellipsize ascii", Ellipsize(20), "TruckRepairServiceOverlordManager", "TruckRepa…ordManager"},
+		{"ellipsize wide", Ellipsize(9), "주문처리서비스관리자", "주문…리자"},
+		{"ellipsize emoji", Ellipsize(5), "🚚🔧👍🏽🇯🇵", "🚚…🇯🇵"},
+		{"left pad wide", LeftPad(8), "注文", "    注文"},
+		{"right pad wide", RightPad(8), "注文", "注文    "},
+		{"right pad zwj", RightPad(4), "👩‍🔧", "👩‍🔧  "},
+		{"right pad color", RightPad(4), "\x1b[32mab\x1b[0m", "\x1b[32mab\x1b[0m  "},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			output := test.transformer.Transform(&Context{}, test.input)
+			assert.Equal(t, test.output, output)
+			assert.True(t, utf8.ValidString(output))
+		})
+	}
+}
+
+func TestDisplayWidth(t *testing.T) {
+	tests := []struct {
+		input string
+		width int
+	}{
+		{"hello", 5},
+		{"注文サービス", 12},
+		{"한국어", 6},
+		{"ｆｕｌｌ", 8},
+		{"e\u0301", 1},
+		{"👍🏽", 2},
+		{"👩‍👩‍👧", 2},
+		{"🇯🇵", 2},
+		{"❤️", 2},
+		{"\x1b[31m注\x1b[0m", 2},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.width, DisplayWidth(test.input), "%q", test.input)
+	}
+}
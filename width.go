@@ -0,0 +1,156 @@
+package jl
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// wideRanges are the ranges of runes that terminals display two columns wide: the East Asian Wide and Fullwidth
+// characters, and emoji that default to emoji presentation.
+var wideRanges = [][2]rune{
+	{0x1100, 0x115f}, {0x231a, 0x231b}, {0x2329, 0x232a}, {0x23e9, 0x23ec}, {0x23f0, 0x23f0}, {0x23f3, 0x23f3},
+	{0x25fd, 0x25fe}, {0x2614, 0x2615}, {0x2648, 0x2653}, {0x267f, 0x267f}, {0x2693, 0x2693}, {0x26a1, 0x26a1},
+	{0x26aa, 0x26ab}, {0x26bd, 0x26be}, {0x26c4, 0x26c5}, {0x26ce, 0x26ce}, {0x26d4, 0x26d4}, {0x26ea, 0x26ea},
+	{0x26f2, 0x26f3}, {0x26f5, 0x26f5}, {0x26fa, 0x26fa}, {0x26fd, 0x26fd}, {0x2705, 0x2705}, {0x270a, 0x270b},
+	{0x2728, 0x2728}, {0x274c, 0x274c}, {0x274e, 0x274e}, {0x2753, 0x2755}, {0x2757, 0x2757}, {0x2795, 0x2797},
+	{0x27b0, 0x27b0}, {0x27bf, 0x27bf}, {0x2b1b, 0x2b1c}, {0x2b50, 0x2b50}, {0x2b55, 0x2b55}, {0x2e80, 0x303e},
+	{0x3041, 0x33ff}, {0x3400, 0x4dbf}, {0x4e00, 0x9fff}, {0xa000, 0xa4cf}, {0xa960, 0xa97f}, {0xac00, 0xd7a3},
+	{0xf900, 0xfaff}, {0xfe10, 0xfe19}, {0xfe30, 0xfe6f}, {0xff00, 0xff60}, {0xffe0, 0xffe6}, {0x16fe0, 0x16fe4},
+	{0x17000, 0x18aff}, {0x1b000, 0x1b16f}, {0x1f004, 0x1f004}, {0x1f0cf, 0x1f0cf}, {0x1f18e, 0x1f18e},
+	{0x1f191, 0x1f19a}, {0x1f200, 0x1f251}, {0x1f300, 0x1f64f}, {0x1f680, 0x1f6ff}, {0x1f7e0, 0x1f7eb},
+	{0x1f900, 0x1f9ff}, {0x1fa70, 0x1faff}, {0x20000, 0x2fffd}, {0x30000, 0x3fffd},
+}
+
+func inRanges(ranges [][2]rune, r rune) bool {
+	i := sort.Search(len(ranges), func(i int) bool { return ranges[i][1] >= r })
+	return i < len(ranges) && ranges[i][0] <= r
+}
+
+// runeWidth returns the number of columns a rune takes up when displayed on its own.
+func runeWidth(r rune) int {
+	switch {
+	case r < 0x20 || (r >= 0x7f && r < 0xa0):
+		return 0
+	case r < 0x1100:
+		if unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+			return 0
+		}
+		return 1
+	case inRanges(wideRanges, r):
+		return 2
+	case isGraphemeExtend(r):
+		return 0
+	}
+	return 1
+}
+
+// isGraphemeExtend reports whether the rune combines with the preceding rune into a single grapheme cluster.
+func isGraphemeExtend(r rune) bool {
+	switch {
+	case r == zeroWidthJoiner,
+		r >= 0xfe00 && r <= 0xfe0f, // variation selectors
+		r >= 0xe0100 && r <= 0xe01ef,
+		r >= 0x1f3fb && r <= 0x1f3ff, // emoji skin tone modifiers
+		r >= 0xe0020 && r <= 0xe007f, // tags, used by flag emoji
+		r >= 0x1160 && r <= 0x11ff:   // Hangul medial vowels and final consonants
+		return true
+	}
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc)
+}
+
+const (
+	zeroWidthJoiner        = 0x200d
+	emojiPresentation      = 0xfe0f
+	regionalIndicatorFirst = 0x1f1e6
+	regionalIndicatorLast  = 0x1f1ff
+)
+
+func isRegionalIndicator(r rune) bool {
+	return r >= regionalIndicatorFirst && r <= regionalIndicatorLast
+}
+
+// nextGrapheme returns the size in bytes and the display width of the grapheme cluster at the start of s: a base
+// character along with any combining marks, variation selectors and modifiers following it, an emoji ZWJ sequence, or a
+// flag made of a pair of regional indicators. This is a simplification of the rules in Unicode Standard Annex #29 that
+// covers the characters commonly found in logs.
+func nextGrapheme(s string) (size, width int) {
+	base, size := utf8.DecodeRuneInString(s)
+	if base == '\r' && strings.HasPrefix(s[size:], "\n") {
+		return size + 1, 0
+	}
+	width = runeWidth(base)
+	if isRegionalIndicator(base) {
+		if r, n := utf8.DecodeRuneInString(s[size:]); isRegionalIndicator(r) {
+			return size + n, 2
+		}
+		return size, 1
+	}
+	for size < len(s) {
+		r, n := utf8.DecodeRuneInString(s[size:])
+		if !isGraphemeExtend(r) {
+			break
+		}
+		size += n
+		if r == emojiPresentation && width == 1 {
+			width = 2
+		}
+		if r == zeroWidthJoiner && size < len(s) {
+			_, n = utf8.DecodeRuneInString(s[size:])
+			size += n
+		}
+	}
+	return size, width
+}
+
+// DisplayWidth returns the number of terminal columns needed to display s, ignoring color escape sequences. East
+// Asian wide characters and emoji take up two columns, and combining marks none.
+func DisplayWidth(s string) int {
+	s = StripColor(s)
+	width := 0
+	for len(s) > 0 {
+		size, w := nextGrapheme(s)
+		s = s[size:]
+		width += w
+	}
+	return width
+}
+
+// cutWidth splits s after at most width columns, without splitting grapheme clusters. Color escape sequences are never
+// split, and always kept in head.
+func cutWidth(s string, width int) (head, tail string) {
+	w := 0
+	for i := 0; i < len(s); {
+		if s[i] == '\x1b' {
+			if loc := ansiEscapePrefix.FindStringIndex(s[i:]); loc != nil {
+				i += loc[1]
+				continue
+			}
+		}
+		size, gw := nextGrapheme(s[i:])
+		if w+gw > width {
+			return s[:i], s[i:]
+		}
+		i += size
+		w += gw
+	}
+	return s, ""
+}
+
+// suffixWidth returns the longest suffix of s that fits in width columns, without splitting grapheme clusters.
+func suffixWidth(s string, width int) string {
+	var starts, widths []int
+	for i := 0; i < len(s); {
+		size, w := nextGrapheme(s[i:])
+		starts = append(starts, i)
+		widths = append(widths, w)
+		i += size
+	}
+	start := len(s)
+	for i := len(starts) - 1; i >= 0 && widths[i] <= width; i-- {
+		width -= widths[i]
+		start = starts[i]
+	}
+	return s[start:]
+}
@@ -0,0 +1,75 @@
+package jl
+
+import (
+	"regexp"
+	"strings"
+)
+
+// minWrapWidth is the narrowest column that wrapped text is indented to. If less room than this is left, continuation
+// lines start at the beginning of the line instead.
+const minWrapWidth = 20
+
+// ansiEscapePrefix matches an ANSI SGR escape sequence at the start of a string.
+var ansiEscapePrefix = regexp.MustCompile("^\x1b\\[[0-9;]*m")
+
+// wrapText word wraps text so that no line is wider than width columns. Existing line breaks are kept, and words
+// longer than width are split.
+func wrapText(text string, width int) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		lines = append(lines, wrapLine(paragraph, width)...)
+	}
+	return lines
+}
+
+func wrapLine(text string, width int) []string {
+	var lines []string
+	var line strings.Builder
+	lineWidth := 0
+	for i, word := range strings.Split(text, " ") {
+		wordWidth := DisplayWidth(word)
+		switch {
+		case i == 0:
+		case lineWidth+1+wordWidth <= width:
+			line.WriteByte(' ')
+			lineWidth++
+		default:
+			lines = append(lines, line.String())
+			line.Reset()
+			lineWidth = 0
+		}
+		for lineWidth+wordWidth > width {
+			head, tail := cutWidth(word, width-lineWidth)
+			if DisplayWidth(head) == 0 {
+				// Too narrow for even a single character.
+				break
+			}
+			line.WriteString(head)
+			lines = append(lines, line.String())
+			line.Reset()
+			lineWidth = 0
+			word = tail
+			wordWidth = DisplayWidth(word)
+		}
+		line.WriteString(word)
+		lineWidth += wordWidth
+	}
+	return append(lines, line.String())
+}
+
+// truncateWidth shortens s to fit in width columns, replacing the end with "…" if anything was cut.
+func truncateWidth(s string, width int) string {
+	if DisplayWidth(s) <= width || width <= 0 {
+		return s
+	}
+	head, _ := cutWidth(s, width-1)
+	if strings.Contains(head, "\x1b[") {
+		return head + "…\x1b[0m"
+	}
+	return head + "…"
+}
+
+// lastLineWidth returns the display width of the last line of s.
+func lastLineWidth(s string) int {
+	return DisplayWidth(s[strings.LastIndexByte(s, '\n')+1:])
+}
@@ -0,0 +1,67 @@
+package jl
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// writer is the io.WriteCloser returned by NewWriter.
+type writer struct {
+	printer EntryPrinter
+
+	mu     sync.Mutex
+	buf    []byte
+	closed bool
+}
+
+// NewWriter returns an io.WriteCloser that parses the lines written to it the same way as Parser, and prints them with
+// printer, so that jl can be plugged into loggers that write JSON to an io.Writer. Writes may contain any number of
+// lines, including partial ones, which are printed once they are completed by a newline or the writer is closed. It is
+// safe for concurrent use, though lines written in pieces by concurrent writers may be interleaved. Close prints the
+// last line if it is incomplete, and flushes printer.
+func NewWriter(printer EntryPrinter) io.WriteCloser {
+	return &writer{printer: printer}
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, io.ErrClosedPipe
+	}
+	w.buf = append(w.buf, p...)
+	start := 0
+	for {
+		i := bytes.IndexByte(w.buf[start:], '\n')
+		if i < 0 {
+			break
+		}
+		w.printLine(w.buf[start : start+i])
+		start += i + 1
+	}
+	// Move the incomplete line to the start of the buffer, so that it does not keep growing.
+	w.buf = w.buf[:copy(w.buf, w.buf[start:])]
+	return len(p), nil
+}
+
+func (w *writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if len(w.buf) > 0 {
+		w.printLine(w.buf)
+		w.buf = nil
+	}
+	Flush(w.printer)
+	return nil
+}
+
+// printLine prints a line without its terminating newline, like bufio.ScanLines dropping a trailing carriage return.
+func (w *writer) printLine(line []byte) {
+	line = bytes.TrimSuffix(line, []byte{'\r'})
+	w.printer.Print(NewEntry(append([]byte(nil), line...)))
+}
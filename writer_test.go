@@ -0,0 +1,50 @@
+package jl
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriter(t *testing.T) {
+	printer := &rawPrinter{}
+	w := NewWriter(printer)
+	for _, s := range []string{`{"msg":`, `"a"}` + "\r\n" + `{"msg":"b"}` + "\nnot ", "json\n", "last"} {
+		n, err := w.Write([]byte(s))
+		require.NoError(t, err)
+		assert.Equal(t, len(s), n)
+	}
+	assert.Equal(t, []string{`{"msg":"a"}`, `{"msg":"b"}`, "not json"}, printer.lines)
+	require.NoError(t, w.Close())
+	assert.Equal(t, []string{`{"msg":"a"}`, `{"msg":"b"}`, "not json", "last"}, printer.lines)
+	_, err := w.Write([]byte("more\n"))
+	assert.Error(t, err)
+}
+
+func TestWriter_Concurrent(t *testing.T) {
+	printer := &rawPrinter{}
+	w := NewWriter(printer)
+	var wg sync.WaitGroup
+	var expected []string
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 100; j++ {
+			expected = append(expected, fmt.Sprintf(`{"writer":%d,"n":%d}`, i, j))
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				fmt.Fprintf(w, `{"writer":%d,"n":%d}`+"\n", i, j)
+			}
+		}(i)
+	}
+	wg.Wait()
+	require.NoError(t, w.Close())
+	sort.Strings(expected)
+	sort.Strings(printer.lines)
+	assert.Equal(t, expected, printer.lines)
+}